@@ -0,0 +1,258 @@
+// Package arrangement saves where all the windows are to a file, and
+// puts them back there later.
+package arrangement
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/icccm"
+	"github.com/BurntSushi/xgbutil/xprop"
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/daemon"
+	"github.com/mpasternacki/xdwim/tiler"
+)
+
+const statePrefix = "_NET_WM_STATE_"
+
+// states that are saved and restored, without statePrefix
+var savedStates = []string{
+	"maximized_vert", "maximized_horz", "fullscreen",
+	"above", "below", "sticky", "shaded",
+}
+
+type file struct {
+	Windows []Entry `toml:"windows"`
+}
+
+// Entry is a saved window. Geometry is the outer one, relative to the
+// head. In a saved file, Title is a regular expression; save writes
+// one matching just the title the window had.
+type Entry struct {
+	Class    string   `toml:"class"`
+	Instance string   `toml:"instance"`
+	Role     string   `toml:"role"`
+	Title    string   `toml:"title"`
+	Desktop  int      `toml:"desktop"`
+	Head     int      `toml:"head"` // 1-based
+	X        int      `toml:"x"`
+	Y        int      `toml:"y"`
+	W        int      `toml:"w"`
+	H        int      `toml:"h"`
+	State    []string `toml:"state"`
+
+	title *regexp.Regexp
+}
+
+// compile prepares saved entry's title pattern.
+func (e *Entry) compile() error {
+	var err error
+	if e.title, err = regexp.Compile(e.Title); err != nil {
+		return fmt.Errorf("%v: bad title: %v", e, err)
+	}
+	return nil
+}
+
+func (e *Entry) String() string {
+	return fmt.Sprintf("%s/%s %q", e.Class, e.Instance, e.Title)
+}
+
+// sameWindow tells whether the entries can be the same window, not
+// looking at the title.
+func (e *Entry) sameWindow(o *Entry) bool {
+	return e.Class == o.Class && e.Instance == o.Instance && e.Role == o.Role
+}
+
+// window is an open window, with an entry describing where it is now.
+type window struct {
+	XWin xproto.Window
+	Entry
+}
+
+// current lists all open windows.
+func current(s *xdwim.Session) ([]window, error) {
+	desks, err := daemon.Desktops(s)
+	if err != nil {
+		return nil, err
+	}
+	heads, err := s.Heads()
+	if err != nil {
+		return nil, err
+	}
+
+	var wins []window
+	for _, desk := range desks {
+		for _, dw := range desk.Windows {
+			win := window{XWin: dw.XWin}
+			win.Title = dw.Name
+			win.Desktop = int(desk.Number)
+
+			if wmClass, err := icccm.WmClassGet(s.X, dw.XWin); err == nil {
+				win.Class, win.Instance = wmClass.Class, wmClass.Instance
+			}
+			win.Role, _ = xprop.PropValStr(xprop.GetProperty(s.X, dw.XWin, "WM_WINDOW_ROLE"))
+
+			geom, err := s.WM.Geometry(dw.XWin)
+			if err != nil {
+				log.Printf("WARN: Geometry(%v): %v", dw.XWin, err)
+				continue
+			}
+			hi := xdwim.HeadIndex(heads, geom)
+			win.Head = hi + 1
+			win.X = geom.X() - heads[hi].X()
+			win.Y = geom.Y() - heads[hi].Y()
+			win.W, win.H = geom.Width(), geom.Height()
+
+			for _, st := range s.WM.State(dw.XWin) {
+				name := strings.ToLower(strings.TrimPrefix(st, statePrefix))
+				for _, saved := range savedStates {
+					if name == saved {
+						win.State = append(win.State, name)
+					}
+				}
+			}
+
+			wins = append(wins, win)
+		}
+	}
+
+	return wins, nil
+}
+
+// move is a window to put where an entry says.
+type move struct {
+	win *window
+	to  *Entry
+}
+
+// match pairs saved entries with open windows with the same class,
+// instance and role. Title only breaks ties: windows with title
+// matching entry's pattern are paired first, then any others. Entries
+// with no window get a nil win.
+func match(entries []Entry, wins []window) []move {
+	moves := make([]move, len(entries))
+	used := make([]bool, len(wins))
+	for pass := 0; pass < 2; pass++ {
+		for i := range entries {
+			if moves[i].win != nil {
+				continue
+			}
+			moves[i].to = &entries[i]
+			for j := range wins {
+				if used[j] || !entries[i].sameWindow(&wins[j].Entry) {
+					continue
+				}
+				if pass == 0 && !entries[i].title.MatchString(wins[j].Title) {
+					continue
+				}
+				moves[i].win = &wins[j]
+				used[j] = true
+				break
+			}
+		}
+	}
+	return moves
+}
+
+func hasState(states []string, st string) bool {
+	for _, s := range states {
+		if s == st {
+			return true
+		}
+	}
+	return false
+}
+
+// diff describes what restoring the move changes; nil if nothing.
+func (m *move) diff() []string {
+	from, to := &m.win.Entry, m.to
+	var d []string
+	if from.Desktop != to.Desktop {
+		d = append(d, fmt.Sprintf("desktop %d → %d", from.Desktop, to.Desktop))
+	}
+	if from.Head != to.Head || from.X != to.X || from.Y != to.Y || from.W != to.W || from.H != to.H {
+		d = append(d, fmt.Sprintf("head %d %d,%d %d×%d → head %d %d,%d %d×%d",
+			from.Head, from.X, from.Y, from.W, from.H,
+			to.Head, to.X, to.Y, to.W, to.H))
+	}
+	for _, st := range to.State {
+		if !hasState(from.State, st) {
+			d = append(d, "+"+st)
+		}
+	}
+	for _, st := range from.State {
+		if !hasState(to.State, st) {
+			d = append(d, "-"+st)
+		}
+	}
+	return d
+}
+
+// apply puts the window where the entry says.
+func (m *move) apply(s *xdwim.Session, heads []xrect.Rect) error {
+	xw, from, to := m.win.XWin, &m.win.Entry, m.to
+
+	if from.Desktop != to.Desktop {
+		if err := ewmh.WmDesktopReq(s.X, xw, uint(to.Desktop)); err != nil {
+			return err
+		}
+	}
+
+	// saved head may be gone
+	head := heads[0]
+	if to.Head >= 1 && to.Head <= len(heads) {
+		head = heads[to.Head-1]
+	}
+	x, y := head.X()+to.X, head.Y()+to.Y
+	if x+to.W > head.X()+head.Width() {
+		x = head.X() + head.Width() - to.W
+	}
+	if x < head.X() {
+		x = head.X()
+	}
+	if y+to.H > head.Y()+head.Height() {
+		y = head.Y() + head.Height() - to.H
+	}
+	if y < head.Y() {
+		y = head.Y()
+	}
+	moved := from.Head != to.Head || from.X != to.X || from.Y != to.Y || from.W != to.W || from.H != to.H
+	if moved {
+		// this clears maximized & fullscreen state
+		if err := tiler.Move(s, xw, x, y, to.W, to.H); err != nil {
+			return err
+		}
+	}
+
+	var add, remove []string
+	for _, st := range savedStates {
+		atom := statePrefix + strings.ToUpper(st)
+		want := hasState(to.State, st)
+		have := hasState(from.State, st)
+		// after a move, the WM may not have told yet which states
+		// it cleared; add all that are wanted
+		if want && (moved || !have) {
+			add = append(add, atom)
+		} else if !want && have {
+			remove = append(remove, atom)
+		}
+	}
+	if len(remove) > 0 {
+		if err := s.WM.SetState(xw, ewmh.StateRemove, remove...); err != nil {
+			return err
+		}
+	}
+	if len(add) > 0 {
+		if err := s.WM.SetState(xw, ewmh.StateAdd, add...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package arrangement
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func entry(class, role, title string) Entry {
+	return Entry{Class: class, Instance: strings.ToLower(class), Role: role, Title: title}
+}
+
+func TestMatch(t *testing.T) {
+	wins := []window{
+		{XWin: 1, Entry: entry("Firefox", "browser", "News — Mozilla Firefox")},
+		{XWin: 2, Entry: entry("Firefox", "browser", "Mail (3) — Mozilla Firefox")},
+		{XWin: 3, Entry: entry("URxvt", "", "~/src")},
+		{XWin: 4, Entry: entry("Firefox", "Preferences", "Settings")},
+		{XWin: 5, Entry: entry("URxvt", "", "htop")},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		entries []Entry
+		want    []int // window each entry gets, 0 for none
+	}{
+		{"title breaks the tie",
+			[]Entry{
+				entry("Firefox", "browser", "^Mail"),
+				entry("Firefox", "browser", "^News"),
+			},
+			[]int{2, 1}},
+		{"changed title falls back to any window",
+			[]Entry{
+				entry("Firefox", "browser", "^"+regexp.QuoteMeta("Mail (2) — Mozilla Firefox")+"$"),
+			},
+			[]int{1}},
+		{"role must match",
+			[]Entry{
+				entry("Firefox", "Preferences", "^Settings$"),
+				entry("Firefox", "", ""),
+			},
+			[]int{4, 0}},
+		{"exact matches first, others get the rest",
+			[]Entry{
+				entry("URxvt", "", "^vim$"),
+				entry("URxvt", "", "^htop$"),
+				entry("URxvt", "", "^mutt$"),
+			},
+			[]int{3, 5, 0}},
+		{"unknown class",
+			[]Entry{entry("Emacs", "", "")},
+			[]int{0}},
+	} {
+		for i := range tc.entries {
+			if err := tc.entries[i].compile(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		moves := match(tc.entries, wins)
+		got := make([]int, len(moves))
+		for i, m := range moves {
+			if m.to != &tc.entries[i] {
+				t.Errorf("%s: move %d is to %v", tc.name, i, m.to)
+			}
+			if m.win != nil {
+				got[i] = int(m.win.XWin)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: windows %v; want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	e := entry("URxvt", "", "(unclosed")
+	if err := e.compile(); err == nil {
+		t.Error("compile of a bad pattern: no error")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := Entry{Desktop: 0, Head: 1, X: 0, Y: 0, W: 960, H: 1080, State: []string{"above"}}
+
+	for _, tc := range []struct {
+		name string
+		to   Entry
+		want []string
+	}{
+		{"same", from, nil},
+		{"other desktop",
+			Entry{Desktop: 2, Head: 1, X: 0, Y: 0, W: 960, H: 1080, State: []string{"above"}},
+			[]string{"desktop 0 → 2"}},
+		{"moved and maximized",
+			Entry{Desktop: 0, Head: 2, X: 10, Y: 0, W: 960, H: 1080, State: []string{"maximized_vert"}},
+			[]string{"head 1 0,0 960×1080 → head 2 10,0 960×1080", "+maximized_vert", "-above"}},
+	} {
+		m := move{win: &window{Entry: from}, to: &tc.to}
+		if d := m.diff(); !reflect.DeepEqual(d, tc.want) {
+			t.Errorf("%s: diff = %q; want %q", tc.name, d, tc.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package arrangement
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/mpasternacki/xdwim"
+)
+
+// SaveCommand is the "save" command of xdwim.
+var SaveCommand = xdwim.Command{
+	Name:     "save",
+	Synopsis: "save where all windows are to a file",
+	Run:      Save,
+	Usage:    saveUsage,
+}
+
+// RestoreCommand is the "restore" command of xdwim.
+var RestoreCommand = xdwim.Command{
+	Name:     "restore",
+	Synopsis: "put windows back where a saved file says",
+	Run:      Restore,
+	Usage:    restoreUsage,
+}
+
+func saveUsage(prog string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s FILE\n\nFILE - writes to standard output.\n", prog)
+}
+
+var restoreFlags = flag.NewFlagSet("restore", flag.ContinueOnError)
+
+var (
+	yesFlag    = restoreFlags.Bool("y", false, "don't ask before applying changes")
+	dryRunFlag = restoreFlags.Bool("dry-run", false, "just show what would change")
+)
+
+func restoreUsage(prog string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS] FILE\n\nOptions:\n", prog)
+	restoreFlags.PrintDefaults()
+}
+
+// Save writes desktop, head, geometry and state of every window to a
+// file.
+func Save(s *xdwim.Session, args []string) error {
+	if len(args) != 1 {
+		return xdwim.ErrUsage
+	}
+
+	wins, err := current(s)
+	if err != nil {
+		return err
+	}
+	f := file{Windows: make([]Entry, len(wins))}
+	for i, win := range wins {
+		f.Windows[i] = win.Entry
+		f.Windows[i].Title = "^" + regexp.QuoteMeta(win.Title) + "$"
+	}
+
+	out := os.Stdout
+	if args[0] != "-" {
+		out, err = os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	return toml.NewEncoder(out).Encode(f)
+}
+
+// Restore shows which windows would move, and moves them back to
+// where the file says after asking.
+func Restore(s *xdwim.Session, args []string) error {
+	restoreFlags.Usage = func() {}
+	if err := restoreFlags.Parse(args); err != nil || restoreFlags.NArg() != 1 {
+		return xdwim.ErrUsage
+	}
+
+	var f file
+	if _, err := toml.DecodeFile(restoreFlags.Arg(0), &f); err != nil {
+		return err
+	}
+
+	for i := range f.Windows {
+		if err := f.Windows[i].compile(); err != nil {
+			return err
+		}
+	}
+
+	wins, err := current(s)
+	if err != nil {
+		return err
+	}
+	heads, err := s.Heads()
+	if err != nil {
+		return err
+	}
+
+	var todo []move
+	for _, m := range match(f.Windows, wins) {
+		if m.win == nil {
+			fmt.Printf("%v: not open\n", m.to)
+			continue
+		}
+		if d := m.diff(); len(d) > 0 {
+			fmt.Printf("%v: %s\n", m.to, strings.Join(d, ", "))
+			todo = append(todo, m)
+		}
+	}
+
+	if len(todo) == 0 {
+		fmt.Println("Nothing to change.")
+		return nil
+	}
+	if *dryRunFlag {
+		return nil
+	}
+	if !*yesFlag {
+		fmt.Printf("Move %d windows? [y/N] ", len(todo))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.TrimSpace(strings.ToLower(answer)); a != "y" && a != "yes" {
+			return nil
+		}
+	}
+
+	for _, m := range todo {
+		if err := m.apply(s, heads); err != nil {
+			log.Printf("WARN: %v: %v", m.to, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+// Command switcher is the same as xdwim switch.
+package main
+
+import (
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/switcher"
+)
+
+func main() {
+	xdwim.Main(switcher.Command)
+}
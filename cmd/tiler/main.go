@@ -0,0 +1,11 @@
+// Command tiler is the same as xdwim tile.
+package main
+
+import (
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/tiler"
+)
+
+func main() {
+	xdwim.Main(tiler.Command)
+}
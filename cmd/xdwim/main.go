@@ -0,0 +1,27 @@
+// Command xdwim runs all the xdwim helpers as subcommands.
+package main
+
+import (
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/arrangement"
+	"github.com/mpasternacki/xdwim/daemon"
+	"github.com/mpasternacki/xdwim/focus"
+	"github.com/mpasternacki/xdwim/rules"
+	"github.com/mpasternacki/xdwim/switcher"
+	"github.com/mpasternacki/xdwim/tiler"
+)
+
+func main() {
+	xdwim.Main(
+		switcher.Command,
+		tiler.Command,
+		daemon.Command,
+		rules.Command,
+		arrangement.SaveCommand,
+		arrangement.RestoreCommand,
+		focus.Command,
+		tiler.PointerCommand,
+		tiler.ShotCommand,
+		tiler.ExposeCommand,
+	)
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+type Config struct {
+	Switcher Switcher `toml:"switcher"`
+	Tiler    Tiler    `toml:"tiler"`
+	Shot     Shot     `toml:"shot"`
+	Rules    []Rule   `toml:"rules"`
+}
+
+// UI settings, checked by the tui package.
+type UI struct {
+	// action → keys
+	Keys map[string][]string `toml:"keys"`
+	// name → colour, like "bold yellow"
+	Colors map[string]string `toml:"colors"`
+	// name → characters to draw with
+	Glyphs map[string]string `toml:"glyphs"`
+	// don't close the terminal when it loses focus
+	KeepOpen bool `toml:"keep_open"`
+}
+
+type Switcher struct {
+	UI
+}
+
+type Tiler struct {
+	UI
+	// name → "x,y,w,h" in grid cells
+	Presets map[string]string `toml:"presets"`
+	// name → preset names or rectangles to step through
+	Cycles map[string][]string `toml:"cycles"`
+	// for nudge: pixels ("10"), percent ("5%") or "inc"
+	Step string `toml:"step"`
+}
+
+type Shot struct {
+	// where screenshots go; $HOME if empty
+	Dir string `toml:"dir"`
+	// put screenshots in the clipboard too; needs xclip
+	Clipboard bool `toml:"clipboard"`
+}
+
+// Rule says what to do with new windows that match it. Match fields
+// are regular expressions, except Type; empty ones match anything.
+type Rule struct {
+	Name string `toml:"name"`
+
+	Class    string `toml:"class"`
+	Instance string `toml:"instance"`
+	Title    string `toml:"title"`
+	Role     string `toml:"role"`
+	// _NET_WM_WINDOW_TYPE without the prefix: "normal", "dialog", ...
+	Type string `toml:"type"`
+
+	Desktop *int `toml:"desktop"`
+	// 1-based; 0 is the head window is on
+	Head int `toml:"head"`
+	// preset name or x,y,w,h in grid cells
+	Tile string `toml:"tile"`
+	// _NET_WM_STATE without the prefix: "above", "sticky", ...
+	State []string `toml:"state"`
+	Focus *bool    `toml:"focus"`
+}
+
+// Dir returns xdwim's configuration directory,
+// $XDG_CONFIG_HOME/xdwim.
+func Dir() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(dir, "xdwim")
+}
+
+func Path() string {
+	return filepath.Join(Dir(), "config.toml")
+}
+
+// Load reads the configuration file. It's fine for it not to exist.
+func Load() (*Config, error) {
+	cfg := &Config{}
+
+	path := Path()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("%s: unknown setting %s", path, undecoded[0])
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/desktop"
+)
+
+// Snapshot asks the daemon for its state. It fails if the daemon is
+// not running.
+func Snapshot(s *xdwim.Session) (*State, error) {
+	path, err := Socket(s.Display)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", path, 100*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+
+	if _, err := fmt.Fprintln(conn, "snapshot"); err != nil {
+		return nil, err
+	}
+
+	st := &State{}
+	if err := json.NewDecoder(conn).Decode(st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Desktops returns the desktop list from the daemon if it's running,
+// and reads it from X if it isn't.
+func Desktops(s *xdwim.Session) ([]desktop.Desktop, error) {
+	st, err := Snapshot(s)
+	if err == nil {
+		return st.Desktops, nil
+	}
+	if _, ok := err.(*net.OpError); !ok {
+		// it is running, but something's wrong
+		log.Printf("WARN: daemon: %v", err)
+	}
+	return desktop.Get(s.X, s.WM)
+}
+
+// Recent returns windows in the order they were last focused, most
+// recent first. Only the daemon knows that; without it, it's empty.
+func Recent(s *xdwim.Session) []xproto.Window {
+	st, err := Snapshot(s)
+	if err != nil {
+		return nil
+	}
+	recent := make([]xproto.Window, len(st.Focus))
+	for i, f := range st.Focus {
+		recent[i] = f.XWin
+	}
+	return recent
+}
@@ -0,0 +1,145 @@
+// Package daemon keeps track of windows, desktops and focus history
+// in a long-running process, and serves snapshots of it to the other
+// commands over a unix socket.
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/mpasternacki/xdwim"
+)
+
+var errXClosed = errors.New("X connection closed")
+
+// Command is the "daemon" command of xdwim.
+var Command = xdwim.Command{
+	Name:     "daemon",
+	Synopsis: "track windows and focus history for other commands",
+	Run:      Run,
+	Usage:    usage,
+}
+
+func usage(prog string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s\n", prog)
+}
+
+// Socket returns path of the daemon's socket for the display. It's in
+// $XDG_RUNTIME_DIR if set, or else in /tmp/xdwim-UID, which must be
+// private to the user: in /tmp itself anyone could put a socket first.
+func Socket(display string) (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("xdwim-%d", os.Getuid()))
+		if err := privateDir(dir); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "xdwim-"+display+".sock"), nil
+}
+
+// privateDir creates dir readable only by us, or checks that it's
+// like that if it exists.
+func privateDir(dir string) error {
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return err
+	}
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !fi.IsDir() || fi.Mode().Perm()&0077 != 0 || !ok || int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is not a directory private to this user", dir)
+	}
+	return nil
+}
+
+// Run serves state of the session's display until X connection
+// closes or the daemon is killed.
+func Run(s *xdwim.Session, args []string) error {
+	flags := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	flags.Usage = func() {}
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return xdwim.ErrUsage
+	}
+
+	path, err := Socket(s.Display)
+	if err != nil {
+		return err
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("daemon already running on %s", path)
+	}
+	// left over from a daemon that died
+	os.Remove(path)
+
+	m, err := newModel(s)
+	if err != nil {
+		return err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		l.Close()
+		os.Remove(path)
+		os.Exit(0)
+	}()
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				log.Printf("WARN: accept: %v", err)
+				return
+			}
+			go m.serve(conn)
+		}
+	}()
+
+	return m.loop()
+}
+
+// serve answers requests, one per line.
+func (m *model) serve(conn net.Conn) {
+	defer conn.Close()
+
+	rd := bufio.NewReader(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch req := strings.TrimSpace(line); req {
+		case "snapshot":
+			err = enc.Encode(m.snapshot())
+		default:
+			err = fmt.Errorf("unknown request %q", req)
+		}
+		if err != nil {
+			log.Printf("WARN: %v", err)
+			return
+		}
+	}
+}
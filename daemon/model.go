@@ -0,0 +1,150 @@
+package daemon
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/desktop"
+)
+
+// how many focus changes to remember
+const focusDepth = 64
+
+// State is what the daemon knows; clients get a copy of it.
+type State struct {
+	Desktops []desktop.Desktop
+	// most recently focused window first
+	Focus []Focus
+}
+
+type Focus struct {
+	XWin xproto.Window
+	Time time.Time
+}
+
+// model keeps State up to date from X events.
+type model struct {
+	sess  *xdwim.Session
+	watch *desktop.Watcher
+
+	mu    sync.Mutex
+	state State
+}
+
+func newModel(s *xdwim.Session) (*model, error) {
+	watch, err := desktop.NewWatcher(s.X)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &model{
+		sess:  s,
+		watch: watch,
+	}
+
+	if err := m.rescan(); err != nil {
+		return nil, err
+	}
+	m.focused()
+
+	return m, nil
+}
+
+// snapshot returns a copy of the state that's safe to use while the
+// model changes.
+func (m *model) snapshot() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := State{
+		Desktops: make([]desktop.Desktop, len(m.state.Desktops)),
+		Focus:    append([]Focus(nil), m.state.Focus...),
+	}
+	for i, desk := range m.state.Desktops {
+		st.Desktops[i] = desk
+		st.Desktops[i].Windows = append([]desktop.Window(nil), desk.Windows...)
+	}
+	return st
+}
+
+// rescan reads the desktop list again, and starts listening to new
+// clients.
+func (m *model) rescan() error {
+	desks, err := desktop.Get(m.sess.X, m.sess.WM)
+	if err != nil {
+		return err
+	}
+
+	m.watch.Listen(desks)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state.Desktops = desks
+	focus := m.state.Focus[:0]
+	for _, f := range m.state.Focus {
+		if m.watch.Watched(f.XWin) {
+			focus = append(focus, f)
+		}
+	}
+	m.state.Focus = focus
+
+	return nil
+}
+
+// focused puts the active window on top of focus history.
+func (m *model) focused() {
+	aw, err := ewmh.ActiveWindowGet(m.sess.X)
+	if err != nil || aw == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.state.Focus) > 0 && m.state.Focus[0].XWin == aw {
+		return
+	}
+
+	focus := []Focus{{XWin: aw, Time: time.Now()}}
+	for _, f := range m.state.Focus {
+		if f.XWin != aw && len(focus) < focusDepth {
+			focus = append(focus, f)
+		}
+	}
+	m.state.Focus = focus
+}
+
+// loop handles X events until the connection breaks.
+func (m *model) loop() error {
+	xu := m.sess.X
+	for {
+		ev, xerr := xu.Conn().WaitForEvent()
+		if xerr != nil {
+			// most likely a window that's gone
+			log.Printf("WARN: X error: %v", xerr)
+			continue
+		}
+		if ev == nil {
+			return errXClosed
+		}
+
+		if m.watch.Changed(ev) {
+			if err := m.rescan(); err != nil {
+				log.Printf("WARN: rescan: %v", err)
+			}
+		}
+
+		switch ev := ev.(type) {
+		case xproto.PropertyNotifyEvent:
+			if ev.Window == xu.RootWin() {
+				m.focused()
+			}
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package desktop
 
 import (
 	"fmt"
@@ -8,25 +8,27 @@ import (
 	"github.com/BurntSushi/xgbutil"
 	"github.com/BurntSushi/xgbutil/ewmh"
 	"github.com/BurntSushi/xgbutil/icccm"
+
+	"github.com/mpasternacki/xdwim/netwm"
 )
 
-type WMWindow struct {
+type Window struct {
 	XWin     xproto.Window
 	IsActive bool
 	IsUrgent bool
 	Name     string
 }
 
-type WMDesktop struct {
+type Desktop struct {
 	Number    uint
 	Name      string
 	Selected  int
 	IsCurrent bool
 	IsUrgent  bool
-	Windows   []WMWindow
+	Windows   []Window
 }
 
-func (wmw WMWindow) String() string {
+func (wmw Window) String() string {
 	activeFlag := ""
 	if wmw.IsActive {
 		activeFlag = "*"
@@ -40,7 +42,7 @@ func (wmw WMWindow) String() string {
 	return fmt.Sprintf("%v%v%#v", activeFlag, urgentFlag, wmw.Name)
 }
 
-func (wmd WMDesktop) String() string {
+func (wmd Desktop) String() string {
 	currentFlag := ""
 	if wmd.IsCurrent {
 		currentFlag = "*"
@@ -59,17 +61,17 @@ func (wmd WMDesktop) String() string {
 	return fmt.Sprintf("%s%s%d%v", currentFlag, urgentFlag, wmd.Number, wins)
 }
 
-func (desk *WMDesktop) IsVisible() bool {
+func (desk *Desktop) IsVisible() bool {
 	return len(desk.Windows) > 0 || desk.IsCurrent
 }
 
-func (desk *WMDesktop) Next() {
+func (desk *Desktop) Next() {
 	if desk.Selected < len(desk.Windows)-1 {
 		desk.Selected++
 	}
 }
 
-func (desk *WMDesktop) NextWrap() {
+func (desk *Desktop) NextWrap() {
 	if desk.Selected < len(desk.Windows)-1 {
 		desk.Selected++
 	} else {
@@ -77,13 +79,13 @@ func (desk *WMDesktop) NextWrap() {
 	}
 }
 
-func (desk *WMDesktop) Prev() {
+func (desk *Desktop) Prev() {
 	if desk.Selected > 0 {
 		desk.Selected--
 	}
 }
 
-func (desk *WMDesktop) PrevWrap() {
+func (desk *Desktop) PrevWrap() {
 	if desk.Selected > 0 {
 		desk.Selected--
 	} else {
@@ -91,17 +93,18 @@ func (desk *WMDesktop) PrevWrap() {
 	}
 }
 
-func (desk *WMDesktop) Window() *WMWindow {
+func (desk *Desktop) Window() *Window {
 	return &desk.Windows[desk.Selected]
 }
 
-func Desktops(xu *xgbutil.XUtil) ([]WMDesktop, error) {
+// Get lists all desktops with their client windows.
+func Get(xu *xgbutil.XUtil, wm *netwm.WM) ([]Desktop, error) {
 	ndesk, err := ewmh.NumberOfDesktopsGet(xu)
 	if err != nil {
 		return nil, err
 	}
 
-	desktops := make([]WMDesktop, ndesk)
+	desktops := make([]Desktop, ndesk)
 
 	names, err := ewmh.DesktopNamesGet(xu)
 	if err != nil {
@@ -124,9 +127,17 @@ func Desktops(xu *xgbutil.XUtil) ([]WMDesktop, error) {
 		return nil, err
 	}
 
-	// TODO: check whether ClientListStackingGet is supported (cwm
-	// doesn't support it)
-	xws, err := ewmh.ClientListGet(xu)
+	var xws []xproto.Window
+	if wm.Supports("_NET_CLIENT_LIST_STACKING") {
+		// bottom to top; list topmost windows first
+		xws, err = ewmh.ClientListStackingGet(xu)
+		for i, j := 0, len(xws)-1; i < j; i, j = i+1, j-1 {
+			xws[i], xws[j] = xws[j], xws[i]
+		}
+	} else {
+		// cwm doesn't support the stacking list
+		xws, err = ewmh.ClientListGet(xu)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +163,7 @@ func Desktops(xu *xgbutil.XUtil) ([]WMDesktop, error) {
 			return nil, err
 		}
 
-		desktops[desk].Windows = append(desktops[desk].Windows, WMWindow{
+		desktops[desk].Windows = append(desktops[desk].Windows, Window{
 			XWin:     xw,
 			IsActive: isActive,
 			IsUrgent: isUrgent,
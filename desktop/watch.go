@@ -0,0 +1,122 @@
+package desktop
+
+import (
+	"log"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/xprop"
+)
+
+// properties on root window that change the desktop list
+var rootProps = []string{
+	"_NET_CLIENT_LIST",
+	"_NET_CLIENT_LIST_STACKING",
+	"_NET_ACTIVE_WINDOW",
+	"_NET_CURRENT_DESKTOP",
+	"_NET_NUMBER_OF_DESKTOPS",
+	"_NET_DESKTOP_NAMES",
+}
+
+// properties on client windows that change the desktop list
+var clientProps = []string{
+	"_NET_WM_DESKTOP",
+	"_NET_WM_NAME",
+	"WM_NAME",
+	"WM_HINTS", // urgency
+}
+
+// Watcher selects events that tell when the desktop list changes:
+// windows opening, closing, being renamed, moving between desktops or
+// turning urgent, and focus changes.
+type Watcher struct {
+	xu          *xgbutil.XUtil
+	rootAtoms   map[xproto.Atom]bool
+	clientAtoms map[xproto.Atom]bool
+	clients     map[xproto.Window]bool
+}
+
+func NewWatcher(xu *xgbutil.XUtil) (*Watcher, error) {
+	w := &Watcher{
+		xu:          xu,
+		rootAtoms:   make(map[xproto.Atom]bool),
+		clientAtoms: make(map[xproto.Atom]bool),
+		clients:     make(map[xproto.Window]bool),
+	}
+
+	for _, name := range rootProps {
+		atom, err := xprop.Atm(xu, name)
+		if err != nil {
+			return nil, err
+		}
+		w.rootAtoms[atom] = true
+	}
+	for _, name := range clientProps {
+		atom, err := xprop.Atm(xu, name)
+		if err != nil {
+			return nil, err
+		}
+		w.clientAtoms[atom] = true
+	}
+
+	err := xproto.ChangeWindowAttributesChecked(xu.Conn(), xu.RootWin(),
+		xproto.CwEventMask, []uint32{xproto.EventMaskPropertyChange}).Check()
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Listen starts watching windows on the desktops that aren't watched
+// yet, and forgets the ones that are gone. It returns the new ones.
+func (w *Watcher) Listen(desks []Desktop) []xproto.Window {
+	alive := make(map[xproto.Window]bool)
+	var added []xproto.Window
+	for _, desk := range desks {
+		for _, win := range desk.Windows {
+			alive[win.XWin] = true
+			if w.clients[win.XWin] {
+				continue
+			}
+
+			err := xproto.ChangeWindowAttributesChecked(w.xu.Conn(), win.XWin, xproto.CwEventMask,
+				[]uint32{xproto.EventMaskPropertyChange | xproto.EventMaskStructureNotify}).Check()
+			if err != nil {
+				// it may be gone already
+				log.Printf("WARN: listen(%v): %v", win.XWin, err)
+				continue
+			}
+			w.clients[win.XWin] = true
+			added = append(added, win.XWin)
+		}
+	}
+
+	for xw := range w.clients {
+		if !alive[xw] {
+			delete(w.clients, xw)
+		}
+	}
+
+	return added
+}
+
+// Watched tells whether the window is a client that's watched.
+func (w *Watcher) Watched(xw xproto.Window) bool {
+	return w.clients[xw]
+}
+
+// Changed tells whether the event may change the desktop list.
+func (w *Watcher) Changed(ev xgb.Event) bool {
+	switch ev := ev.(type) {
+	case xproto.PropertyNotifyEvent:
+		if ev.Window == w.xu.RootWin() {
+			return w.rootAtoms[ev.Atom]
+		}
+		return w.clientAtoms[ev.Atom]
+	case xproto.DestroyNotifyEvent:
+		return w.clients[ev.Window]
+	}
+	return false
+}
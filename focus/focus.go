@@ -0,0 +1,179 @@
+// Package focus activates the window next to the active one in a
+// direction, like tiling WMs do.
+package focus
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/daemon"
+)
+
+// Command is the "focus" command of xdwim.
+var Command = xdwim.Command{
+	Name:     "focus",
+	Synopsis: "focus the window to the left, right, up or down",
+	Run:      Run,
+	Usage:    usage,
+}
+
+var flags = flag.NewFlagSet("focus", flag.ContinueOnError)
+
+var crossFlag = flags.Bool("cross", false, "go to the next monitor if there's nothing in that direction on this one")
+
+func usage(prog string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS] left|right|up|down\n\nOptions:\n", prog)
+	flags.PrintDefaults()
+}
+
+var errNothing = errors.New("no window in that direction")
+
+// span is a rectangle turned so that the direction is towards
+// growing a: a0-a1 along the direction, p0-p1 across it.
+type span struct {
+	a0, a1, p0, p1 int
+}
+
+func orient(r xrect.Rect, dir string) span {
+	x0, y0 := r.X(), r.Y()
+	x1, y1 := x0+r.Width(), y0+r.Height()
+	switch dir {
+	case "left":
+		return span{-x1, -x0, y0, y1}
+	case "up":
+		return span{-y1, -y0, x0, x1}
+	case "down":
+		return span{y0, y1, x0, x1}
+	default: // right
+		return span{x0, x1, y0, y1}
+	}
+}
+
+// score tells how good c is as the next window after a, lower is
+// better; ok is false if c is not in the direction at all.
+func score(a, c span) (s int, ok bool) {
+	// c's center must be further, and it must stick out ahead of a
+	if c.a0+c.a1 <= a.a0+a.a1 || c.a1 <= a.a1 {
+		return 0, false
+	}
+
+	dist := c.a0 - a.a1
+	if dist < 0 {
+		dist = 0
+	}
+
+	overlap := min(a.p1, c.p1) - max(a.p0, c.p0)
+	gap := 0
+	if overlap < 0 {
+		gap, overlap = -overlap, 0
+	}
+
+	// being beside a is worth more than being close
+	return dist + 2*gap - overlap/4, true
+}
+
+type candidate struct {
+	xw   xproto.Window
+	geom xrect.Rect
+	head int
+}
+
+// Run activates the best window in the direction.
+func Run(s *xdwim.Session, args []string) error {
+	flags.Usage = func() {}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return xdwim.ErrUsage
+	}
+	dir := flags.Arg(0)
+	switch dir {
+	case "left", "right", "up", "down":
+	default:
+		return xdwim.ErrUsage
+	}
+
+	aw, err := s.ActiveWindow()
+	if err != nil {
+		return err
+	}
+	ageom, err := s.WM.Geometry(aw)
+	if err != nil {
+		return err
+	}
+	heads, err := s.Heads()
+	if err != nil {
+		return err
+	}
+	ahead := xdwim.HeadIndex(heads, ageom)
+
+	desks, err := daemon.Desktops(s)
+	if err != nil {
+		return err
+	}
+	var cands []candidate
+	for _, desk := range desks {
+		if !desk.IsCurrent {
+			continue
+		}
+		for _, win := range desk.Windows {
+			if win.XWin == aw || !s.WM.IsNormal(win.XWin) {
+				continue
+			}
+			geom, err := s.WM.Geometry(win.XWin)
+			if err != nil {
+				log.Printf("WARN: Geometry(%v): %v", win.XWin, err)
+				continue
+			}
+			cands = append(cands, candidate{win.XWin, geom, xdwim.HeadIndex(heads, geom)})
+		}
+	}
+
+	xw, ok := best(cands, ageom, dir, func(c candidate) bool { return c.head == ahead })
+	if !ok && *crossFlag {
+		// the nearest head in the direction, then the best window on it
+		a := orient(heads[ahead], dir)
+		nearest, nearestScore := -1, 0
+		for i, head := range heads {
+			if sc, ok := score(a, orient(head, dir)); ok && (nearest < 0 || sc < nearestScore) {
+				nearest, nearestScore = i, sc
+			}
+		}
+		if nearest >= 0 {
+			xw, ok = best(cands, ageom, dir, func(c candidate) bool { return c.head == nearest })
+			if !ok {
+				// nothing in the direction from the window; take the
+				// one closest to it on that head
+				xw, ok = best(cands, heads[ahead], dir, func(c candidate) bool { return c.head == nearest })
+			}
+		}
+	}
+	if !ok {
+		return errNothing
+	}
+
+	return ewmh.ActiveWindowReq(s.X, xw)
+}
+
+// best returns the candidate passing the filter with the best score
+// from r in the direction.
+func best(cands []candidate, r xrect.Rect, dir string, filter func(candidate) bool) (xproto.Window, bool) {
+	a := orient(r, dir)
+	var bestWin xproto.Window
+	bestScore, found := 0, false
+	for _, c := range cands {
+		if !filter(c) {
+			continue
+		}
+		if sc, ok := score(a, orient(c.geom, dir)); ok && (!found || sc < bestScore) {
+			bestWin, bestScore, found = c.xw, sc, true
+		}
+	}
+	return bestWin, found
+}
@@ -0,0 +1,95 @@
+package focus
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/xrect"
+)
+
+func TestOrient(t *testing.T) {
+	r := xrect.New(100, 200, 300, 400)
+	for _, tc := range []struct {
+		dir  string
+		want span
+	}{
+		{"right", span{100, 400, 200, 600}},
+		{"left", span{-400, -100, 200, 600}},
+		{"down", span{200, 600, 100, 400}},
+		{"up", span{-600, -200, 100, 400}},
+	} {
+		if s := orient(r, tc.dir); s != tc.want {
+			t.Errorf("orient(%s) = %v; want %v", tc.dir, s, tc.want)
+		}
+	}
+}
+
+func TestBest(t *testing.T) {
+	quarters := []candidate{
+		{1, xrect.New(0, 0, 960, 540), 0},
+		{2, xrect.New(960, 0, 960, 540), 0},
+		{3, xrect.New(0, 540, 960, 540), 0},
+		{4, xrect.New(960, 540, 960, 540), 0},
+	}
+	all := func(candidate) bool { return true }
+
+	for _, tc := range []struct {
+		name   string
+		cands  []candidate
+		active int // index into cands
+		dir    string
+		want   xproto.Window // 0 for none
+	}{
+		{"quarters, right", quarters, 0, "right", 2},
+		{"quarters, down", quarters, 0, "down", 3},
+		{"quarters, left from the edge", quarters, 0, "left", 0},
+		{"quarters, up from the edge", quarters, 0, "up", 0},
+		{"quarters, left", quarters, 3, "left", 3},
+		{"quarters, up", quarters, 3, "up", 2},
+		{"beside beats diagonal and closer",
+			[]candidate{
+				{1, xrect.New(0, 0, 500, 500), 0},
+				{2, xrect.New(600, 600, 300, 300), 0},
+				{3, xrect.New(800, 0, 300, 500), 0},
+			}, 0, "right", 3},
+		{"nearest of two beside",
+			[]candidate{
+				{1, xrect.New(0, 0, 500, 500), 0},
+				{2, xrect.New(1200, 0, 300, 500), 0},
+				{3, xrect.New(600, 0, 300, 500), 0},
+			}, 0, "right", 3},
+		{"overlapping window more to the right",
+			[]candidate{
+				{1, xrect.New(0, 0, 800, 500), 0},
+				{2, xrect.New(600, 100, 800, 300), 0},
+			}, 0, "right", 2},
+		{"underneath isn't to the right",
+			[]candidate{
+				{1, xrect.New(0, 0, 800, 500), 0},
+				{2, xrect.New(100, 100, 600, 300), 0},
+			}, 0, "right", 0},
+	} {
+		var others []candidate
+		for i, c := range tc.cands {
+			if i != tc.active {
+				others = append(others, c)
+			}
+		}
+
+		xw, ok := best(others, tc.cands[tc.active].geom, tc.dir, all)
+		if ok != (tc.want != 0) || xw != tc.want {
+			t.Errorf("%s: best = %v, %v; want %v", tc.name, xw, ok, tc.want)
+		}
+	}
+}
+
+func TestBestFilter(t *testing.T) {
+	cands := []candidate{
+		{2, xrect.New(600, 0, 300, 500), 0},
+		{3, xrect.New(2000, 0, 300, 500), 1},
+	}
+	xw, ok := best(cands, xrect.New(0, 0, 500, 500), "right", func(c candidate) bool { return c.head == 1 })
+	if !ok || xw != 3 {
+		t.Errorf("best on head 1 = %v, %v; want 3", xw, ok)
+	}
+}
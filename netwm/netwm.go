@@ -0,0 +1,129 @@
+package netwm
+
+import (
+	"log"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xrect"
+	"github.com/BurntSushi/xgbutil/xwindow"
+)
+
+// Source indication for EWMH client messages: we act on behalf of
+// the user, like a pager would.
+const sourcePager = 2
+
+// WM knows which EWMH hints the running window manager advertises in
+// _NET_SUPPORTED, so callers can pick a strategy the WM will honour.
+type WM struct {
+	X         *xgbutil.XUtil
+	supported map[string]bool
+}
+
+func Detect(xu *xgbutil.XUtil) (*WM, error) {
+	wm := &WM{
+		X:         xu,
+		supported: make(map[string]bool),
+	}
+
+	atoms, err := ewmh.SupportedGet(xu)
+	if err != nil {
+		// Not an EWMH WM at all; everything will use the fallbacks.
+		log.Printf("WARN: SupportedGet: %v", err)
+		return wm, nil
+	}
+
+	for _, atom := range atoms {
+		wm.supported[atom] = true
+	}
+
+	return wm, nil
+}
+
+func (wm *WM) Supports(atom string) bool {
+	return wm.supported[atom]
+}
+
+// FrameExtents returns the size of the decorations the WM put around
+// win. If the WM doesn't tell, they're guessed from the frame window.
+func (wm *WM) FrameExtents(win xproto.Window) ewmh.FrameExtents {
+	if wm.Supports("_NET_FRAME_EXTENTS") {
+		ext, err := ewmh.FrameExtentsGet(wm.X, win)
+		if err == nil {
+			return *ext
+		}
+		log.Printf("WARN: FrameExtentsGet(%v): %v", win, err)
+	}
+
+	client, err := wm.clientGeometry(win)
+	if err != nil {
+		return ewmh.FrameExtents{}
+	}
+
+	frame, err := xwindow.New(wm.X, win).DecorGeometry()
+	if err != nil {
+		return ewmh.FrameExtents{}
+	}
+
+	return ewmh.FrameExtents{
+		Left:   client.X() - frame.X(),
+		Right:  frame.X() + frame.Width() - client.X() - client.Width(),
+		Top:    client.Y() - frame.Y(),
+		Bottom: frame.Y() + frame.Height() - client.Y() - client.Height(),
+	}
+}
+
+// clientGeometry returns win's own geometry in root coordinates.
+func (wm *WM) clientGeometry(win xproto.Window) (xrect.Rect, error) {
+	geom, err := xwindow.New(wm.X, win).Geometry()
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := xproto.TranslateCoordinates(wm.X.Conn(), win, wm.X.RootWin(), 0, 0).Reply()
+	if err != nil {
+		return nil, err
+	}
+
+	return xrect.New(int(pos.DstX), int(pos.DstY), geom.Width(), geom.Height()), nil
+}
+
+// Geometry returns win's outer geometry, including the frame, in root
+// coordinates. This is what MoveResize expects back.
+func (wm *WM) Geometry(win xproto.Window) (xrect.Rect, error) {
+	geom, err := wm.clientGeometry(win)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := wm.FrameExtents(win)
+	return xrect.New(
+		geom.X()-ext.Left,
+		geom.Y()-ext.Top,
+		geom.Width()+ext.Left+ext.Right,
+		geom.Height()+ext.Top+ext.Bottom,
+	), nil
+}
+
+// MoveResize places win so that its frame covers the given outer
+// rectangle. It uses _NET_MOVERESIZE_WINDOW when the WM supports it
+// and configures the client directly otherwise.
+func (wm *WM) MoveResize(win xproto.Window, x, y, w, h int) error {
+	ext := wm.FrameExtents(win)
+	w -= ext.Left + ext.Right
+	h -= ext.Top + ext.Bottom
+
+	if wm.Supports("_NET_MOVERESIZE_WINDOW") {
+		// NorthWest gravity: x, y is the outer corner of the frame.
+		return ewmh.MoveresizeWindowExtra(wm.X, win, x, y, w, h,
+			xproto.GravityNorthWest, sourcePager, true, true)
+	}
+
+	// ICCCM: with the default NorthWest win_gravity the WM will put
+	// the frame's corner at x, y.
+	return xproto.ConfigureWindowChecked(wm.X.Conn(), win,
+		xproto.ConfigWindowX|xproto.ConfigWindowY|
+			xproto.ConfigWindowWidth|xproto.ConfigWindowHeight,
+		[]uint32{uint32(x), uint32(y), uint32(w), uint32(h)}).Check()
+}
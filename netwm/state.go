@@ -0,0 +1,85 @@
+package netwm
+
+import (
+	"log"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+)
+
+const (
+	StateMaximizedHorz = "_NET_WM_STATE_MAXIMIZED_HORZ"
+	StateMaximizedVert = "_NET_WM_STATE_MAXIMIZED_VERT"
+	StateFullscreen    = "_NET_WM_STATE_FULLSCREEN"
+)
+
+// PlacementStates are the states in which the WM, not the client,
+// owns the window's geometry.
+var PlacementStates = []string{StateMaximizedHorz, StateMaximizedVert, StateFullscreen}
+
+// State returns win's _NET_WM_STATE. A missing property is not an
+// error, it just means no state is set.
+func (wm *WM) State(win xproto.Window) []string {
+	states, err := ewmh.WmStateGet(wm.X, win)
+	if err != nil {
+		if !NoProperty(err) {
+			log.Printf("WARN: WmStateGet(%v): %v", win, err)
+		}
+		return nil
+	}
+	return states
+}
+
+// NoProperty tells whether err is xprop's way of saying a property is
+// not set on the window, which is normal for optional hints.
+func NoProperty(err error) bool {
+	return strings.Contains(err.Error(), "No such property")
+}
+
+// PlacementState returns those of PlacementStates that are set on win.
+func (wm *WM) PlacementState(win xproto.Window) []string {
+	var rv []string
+	for _, state := range wm.State(win) {
+		for _, ps := range PlacementStates {
+			if state == ps {
+				rv = append(rv, state)
+			}
+		}
+	}
+	return rv
+}
+
+// SetState asks the WM to add, remove or toggle (ewmh.StateAdd,
+// ewmh.StateRemove, ewmh.StateToggle) the given states on win.
+func (wm *WM) SetState(win xproto.Window, action int, states ...string) error {
+	// one message carries at most two properties
+	for i := 0; i < len(states); i += 2 {
+		second := ""
+		if i+1 < len(states) {
+			second = states[i+1]
+		}
+		err := ewmh.WmStateReqExtra(wm.X, win, action, states[i], second, sourcePager)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsNormal tells whether win is a normal application window that is
+// not hidden (minimized).
+func (wm *WM) IsNormal(win xproto.Window) bool {
+	types, err := ewmh.WmWindowTypeGet(wm.X, win)
+	if err == nil && len(types) > 0 && types[0] != "_NET_WM_WINDOW_TYPE_NORMAL" {
+		return false
+	}
+
+	for _, state := range wm.State(win) {
+		if state == "_NET_WM_STATE_HIDDEN" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xprop"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/config"
+	"github.com/mpasternacki/xdwim/tiler"
+)
+
+// Command is the "rules" command of xdwim.
+var Command = xdwim.Command{
+	Name:     "rules",
+	Synopsis: "place new windows according to rules",
+	Run:      Run,
+	Usage:    usage,
+}
+
+var flags = flag.NewFlagSet("rules", flag.ContinueOnError)
+
+var (
+	dryRunFlag   = flags.Bool("dry-run", false, "print what would be done instead of doing it")
+	existingFlag = flags.Bool("existing", false, "apply rules to windows that already exist, too")
+	onceFlag     = flags.Bool("once", false, "apply rules to existing windows and exit")
+)
+
+func usage(prog string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS]\n\nRules are [[rules]] tables in %s\n\nOptions:\n", prog, config.Path())
+	flags.PrintDefaults()
+}
+
+// Run watches for new windows and applies the first rule that matches
+// to each.
+func Run(s *xdwim.Session, args []string) error {
+	flags.Usage = func() {}
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return xdwim.ErrUsage
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := tiler.LoadPresets(cfg.Tiler); err != nil {
+		return err
+	}
+	rules, err := compile(cfg.Rules)
+	if err != nil {
+		return fmt.Errorf("%s: %v", config.Path(), err)
+	}
+	if len(rules) == 0 {
+		return fmt.Errorf("%s: no [[rules]]", config.Path())
+	}
+
+	xu := s.X
+	if !*onceFlag {
+		err := xproto.ChangeWindowAttributesChecked(xu.Conn(), xu.RootWin(),
+			xproto.CwEventMask, []uint32{xproto.EventMaskPropertyChange}).Check()
+		if err != nil {
+			return err
+		}
+	}
+	clientList, err := xprop.Atm(xu, "_NET_CLIENT_LIST")
+	if err != nil {
+		return err
+	}
+	activeWindow, err := xprop.Atm(xu, "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return err
+	}
+
+	known := make(map[xproto.Window]bool)
+	xws, err := ewmh.ClientListGet(xu)
+	if err != nil {
+		return err
+	}
+	active, _ := ewmh.ActiveWindowGet(xu)
+	for _, xw := range xws {
+		known[xw] = true
+		if *existingFlag || *onceFlag {
+			handle(s, rules, xw, active)
+		}
+	}
+	if *onceFlag {
+		return nil
+	}
+
+	for {
+		ev, xerr := xu.Conn().WaitForEvent()
+		if xerr != nil {
+			log.Printf("WARN: X error: %v", xerr)
+			continue
+		}
+		if ev == nil {
+			return errors.New("X connection closed")
+		}
+
+		pn, ok := ev.(xproto.PropertyNotifyEvent)
+		if !ok || pn.Window != xu.RootWin() {
+			continue
+		}
+
+		switch pn.Atom {
+		case activeWindow:
+			// remember it, before a new window takes focus
+			if aw, err := ewmh.ActiveWindowGet(xu); err == nil && known[aw] {
+				active = aw
+			}
+		case clientList:
+			xws, err := ewmh.ClientListGet(xu)
+			if err != nil {
+				log.Printf("WARN: ClientListGet: %v", err)
+				continue
+			}
+			alive := make(map[xproto.Window]bool, len(xws))
+			for _, xw := range xws {
+				alive[xw] = true
+				if !known[xw] {
+					known[xw] = true
+					handle(s, rules, xw, active)
+				}
+			}
+			for xw := range known {
+				if !alive[xw] {
+					delete(known, xw)
+				}
+			}
+		}
+	}
+}
+
+// handle applies the matching rule to a window, or just prints it in
+// dry run.
+func handle(s *xdwim.Session, rules []rule, xw, prev xproto.Window) {
+	win := getWindow(s, xw)
+	r := match(rules, win)
+	if r == nil {
+		if *dryRunFlag {
+			fmt.Printf("%#x %s (%s/%s): no rule\n", xw, win.Title, win.Class, win.Instance)
+		}
+		return
+	}
+
+	if *dryRunFlag {
+		fmt.Printf("%#x %s (%s/%s): rule %s: %s\n", xw, win.Title, win.Class, win.Instance, r.Name, r.actions())
+		return
+	}
+
+	if err := r.apply(s, xw, prev); err != nil {
+		log.Printf("WARN: rule %s on %#x: %v", r.Name, xw, err)
+	}
+}
@@ -0,0 +1,237 @@
+// Package rules places new windows according to rules from the config
+// file: moves them to a desktop, tiles them, sets their state and
+// focuses them or not.
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/icccm"
+	"github.com/BurntSushi/xgbutil/xprop"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/config"
+	"github.com/mpasternacki/xdwim/tiler"
+)
+
+var windowTypes = []string{
+	"desktop", "dock", "toolbar", "menu", "utility", "splash", "dialog",
+	"dropdown_menu", "popup_menu", "tooltip", "notification", "combo",
+	"dnd", "normal",
+}
+
+var states = []string{
+	"modal", "sticky", "maximized_vert", "maximized_horz", "shaded",
+	"skip_taskbar", "skip_pager", "hidden", "fullscreen", "above",
+	"below", "demands_attention",
+}
+
+// rule is config.Rule with regular expressions compiled.
+type rule struct {
+	config.Rule
+	class, instance, title, role *regexp.Regexp
+}
+
+// window is what rules match on.
+type window struct {
+	XWin     xproto.Window
+	Class    string
+	Instance string
+	Title    string
+	Role     string
+	Types    []string
+}
+
+func known(list []string, name string) bool {
+	for _, n := range list {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func compile(cfgs []config.Rule) ([]rule, error) {
+	rules := make([]rule, len(cfgs))
+	for i, cfg := range cfgs {
+		r := &rules[i]
+		r.Rule = cfg
+		name := cfg.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		for _, re := range []struct {
+			field   string
+			pattern string
+			dest    **regexp.Regexp
+		}{
+			{"class", cfg.Class, &r.class},
+			{"instance", cfg.Instance, &r.instance},
+			{"title", cfg.Title, &r.title},
+			{"role", cfg.Role, &r.role},
+		} {
+			if re.pattern == "" {
+				continue
+			}
+			var err error
+			if *re.dest, err = regexp.Compile(re.pattern); err != nil {
+				return nil, fmt.Errorf("rule %s: %s: %v", name, re.field, err)
+			}
+		}
+
+		if cfg.Type != "" && !known(windowTypes, cfg.Type) {
+			return nil, fmt.Errorf("rule %s: unknown type %q (known: %s)", name, cfg.Type, strings.Join(windowTypes, ", "))
+		}
+		for _, st := range cfg.State {
+			if !known(states, st) {
+				return nil, fmt.Errorf("rule %s: unknown state %q (known: %s)", name, st, strings.Join(states, ", "))
+			}
+		}
+		if cfg.Desktop != nil && *cfg.Desktop < 0 {
+			return nil, fmt.Errorf("rule %s: bad desktop %d", name, *cfg.Desktop)
+		}
+		if cfg.Head < 0 {
+			return nil, fmt.Errorf("rule %s: bad head %d", name, cfg.Head)
+		}
+		if cfg.Head > 0 && cfg.Tile == "" {
+			return nil, fmt.Errorf("rule %s: head needs tile", name)
+		}
+		if cfg.Tile != "" {
+			if err := tiler.CheckPreset(cfg.Tile); err != nil {
+				return nil, fmt.Errorf("rule %s: %v", name, err)
+			}
+		}
+
+		r.Name = name
+	}
+	return rules, nil
+}
+
+func getWindow(s *xdwim.Session, xw xproto.Window) window {
+	win := window{XWin: xw}
+
+	if wmClass, err := icccm.WmClassGet(s.X, xw); err == nil {
+		win.Class, win.Instance = wmClass.Class, wmClass.Instance
+	}
+
+	if name, err := ewmh.WmNameGet(s.X, xw); err == nil && name != "" {
+		win.Title = name
+	} else if name, err := icccm.WmNameGet(s.X, xw); err == nil {
+		win.Title = name
+	}
+
+	win.Role, _ = xprop.PropValStr(xprop.GetProperty(s.X, xw, "WM_WINDOW_ROLE"))
+
+	types, _ := ewmh.WmWindowTypeGet(s.X, xw)
+	for _, t := range types {
+		win.Types = append(win.Types, strings.ToLower(strings.TrimPrefix(t, "_NET_WM_WINDOW_TYPE_")))
+	}
+	if len(win.Types) == 0 {
+		if _, err := icccm.WmTransientForGet(s.X, xw); err == nil {
+			win.Types = []string{"dialog"}
+		} else {
+			win.Types = []string{"normal"}
+		}
+	}
+
+	return win
+}
+
+func (r *rule) matches(win window) bool {
+	for _, m := range []struct {
+		re *regexp.Regexp
+		s  string
+	}{
+		{r.class, win.Class},
+		{r.instance, win.Instance},
+		{r.title, win.Title},
+		{r.role, win.Role},
+	} {
+		if m.re != nil && !m.re.MatchString(m.s) {
+			return false
+		}
+	}
+	return r.Type == "" || known(win.Types, r.Type)
+}
+
+// match returns the first rule matching the window, or nil.
+func match(rules []rule, win window) *rule {
+	for i := range rules {
+		if rules[i].matches(win) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// actions describes what the rule does.
+func (r *rule) actions() string {
+	var acts []string
+	if r.Desktop != nil {
+		acts = append(acts, fmt.Sprintf("desktop %d", *r.Desktop))
+	}
+	if r.Tile != "" {
+		if r.Head > 0 {
+			acts = append(acts, fmt.Sprintf("tile %s on head %d", r.Tile, r.Head))
+		} else {
+			acts = append(acts, "tile "+r.Tile)
+		}
+	}
+	if len(r.State) > 0 {
+		acts = append(acts, "state "+strings.Join(r.State, " "))
+	}
+	if r.Focus != nil {
+		if *r.Focus {
+			acts = append(acts, "focus")
+		} else {
+			acts = append(acts, "no focus")
+		}
+	}
+	if len(acts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(acts, ", ")
+}
+
+// apply does what the rule says to the window; prev is the window
+// that was active before it appeared.
+func (r *rule) apply(s *xdwim.Session, xw, prev xproto.Window) error {
+	if r.Desktop != nil {
+		if err := ewmh.WmDesktopReq(s.X, xw, uint(*r.Desktop)); err != nil {
+			return err
+		}
+	}
+
+	if r.Tile != "" {
+		if err := tiler.Tile(s, xw, r.Head, r.Tile); err != nil {
+			return err
+		}
+	}
+
+	if len(r.State) > 0 {
+		atoms := make([]string, len(r.State))
+		for i, st := range r.State {
+			atoms[i] = "_NET_WM_STATE_" + strings.ToUpper(st)
+		}
+		if err := s.WM.SetState(xw, ewmh.StateAdd, atoms...); err != nil {
+			return err
+		}
+	}
+
+	if r.Focus != nil {
+		if *r.Focus {
+			return ewmh.ActiveWindowReq(s.X, xw)
+		}
+		// the WM may have focused it already; give focus back
+		if aw, err := ewmh.ActiveWindowGet(s.X); err == nil && aw == xw && prev != 0 {
+			return ewmh.ActiveWindowReq(s.X, prev)
+		}
+	}
+
+	return nil
+}
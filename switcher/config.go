@@ -0,0 +1,72 @@
+package switcher
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mpasternacki/termbox-go"
+
+	"github.com/mpasternacki/xdwim/config"
+	"github.com/mpasternacki/xdwim/tui"
+)
+
+var defaultKeys = map[string][]string{
+	"cancel":      {"esc", "q"},
+	"left":        {"left", "a"},
+	"right":       {"right", "d"},
+	"up":          {"up", "w"},
+	"down":        {"down", "s"},
+	"next-window": {"tab"},
+	"choose":      {"enter", "space"},
+	"close":       {"backspace"},
+	"active":      {"e"},
+	"urgent":      {"!"},
+	"previous":    {"p"},
+}
+
+func init() {
+	for i := 0; i < 10; i++ {
+		defaultKeys["desktop-"+strconv.Itoa(i)] = []string{strconv.Itoa(i)}
+	}
+}
+
+var defaultColors = map[string]termbox.Attribute{
+	"frame":  termbox.ColorYellow,
+	"title":  termbox.ColorGreen,
+	"urgent": termbox.ColorRed,
+	"window": termbox.ColorDefault,
+}
+
+var defaultGlyphs = map[string]string{
+	// corners: top left, top right, bottom left, bottom right; then
+	// horizontal and vertical line
+	"frame": "╭╮╰╯─│",
+	// desktop numbers
+	"digits": "⁰¹²³⁴⁵⁶⁷⁸⁹",
+}
+
+var (
+	keymap    tui.Keymap
+	colors    map[string]termbox.Attribute
+	glyphs    map[string][]rune
+	urxvtArgs = []string{"-pe", "destroy_on_focus_out"}
+)
+
+// configure sets up keys, colours and glyphs from the [switcher]
+// section of the config file.
+func configure(cfg config.Switcher) error {
+	var err error
+	if keymap, err = tui.NewKeymap(defaultKeys, cfg.Keys); err != nil {
+		return fmt.Errorf("%s: [switcher.keys]: %v", config.Path(), err)
+	}
+	if colors, err = tui.Colors(defaultColors, cfg.Colors); err != nil {
+		return fmt.Errorf("%s: [switcher.colors]: %v", config.Path(), err)
+	}
+	if glyphs, err = tui.Glyphs(defaultGlyphs, cfg.Glyphs); err != nil {
+		return fmt.Errorf("%s: [switcher.glyphs]: %v", config.Path(), err)
+	}
+	if cfg.KeepOpen {
+		urxvtArgs = nil
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package switcher
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/xgbutil/ewmh"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/config"
+	"github.com/mpasternacki/xdwim/daemon"
+)
+
+var cmdCloseWindow = errors.New("CLOSE WINDOW")
+var cmdCancel = errors.New("CANCEL")
+
+// Command is the "switch" command of xdwim.
+var Command = xdwim.Command{
+	Name:     "switch",
+	Synopsis: "pick a window to switch to",
+	Run:      Run,
+	Usage:    usage,
+}
+
+func usage(prog string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s\n", prog)
+}
+
+// Run shows the window list and activates the chosen window.
+func Run(s *xdwim.Session, args []string) error {
+	flags := flag.NewFlagSet("switch", flag.ContinueOnError)
+	flags.Usage = func() {}
+	if err := flags.Parse(args); err != nil {
+		return xdwim.ErrUsage
+	}
+	if flags.NArg() > 0 {
+		return xdwim.ErrUsage
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := configure(cfg.Switcher); err != nil {
+		return err
+	}
+
+	xu := s.X
+	desks, err := daemon.Desktops(s)
+	if err != nil {
+		return err
+	}
+
+	ui := NewUIState(desks)
+	ui.Recent = daemon.Recent(s)
+
+	stop, err := watch(s, desks)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	err = ui.Main()
+	if err == nil || err == cmdCloseWindow {
+		if desk := ui.Desk(); desk == nil || len(desk.Windows) == 0 {
+			// nothing to choose or close
+			return nil
+		}
+	}
+
+	switch err {
+	case cmdCancel:
+		return nil
+	case cmdCloseWindow:
+		xw := ui.Desk().Window().XWin
+		return ewmh.CloseWindow(xu, xw)
+	case nil:
+		// default: choose window
+		desk := ui.Desk()
+		win := desk.Window()
+		xw := win.XWin
+
+		err = ewmh.CurrentDesktopReq(xu, int(desk.Number))
+		if err != nil {
+			return err
+		}
+
+		return ewmh.ActiveWindowReq(xu, xw)
+	default:
+		return err
+	}
+}
@@ -1,23 +1,30 @@
-package main
+package switcher
 
 import (
 	"errors"
 	"log"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
-	"../urxvtermbox"
+	"github.com/BurntSushi/xgb/xproto"
 	"github.com/mpasternacki/termbox-go"
+
+	"github.com/mpasternacki/xdwim/desktop"
+	"github.com/mpasternacki/xdwim/tui"
+	"github.com/mpasternacki/xdwim/urxvtermbox"
 )
 
 type UIState struct {
-	Desktops []WMDesktop
+	Desktops []desktop.Desktop
 	Selected int
 	Height   int
 	Width    int
+	// windows by when they were last focused, most recent first
+	Recent []xproto.Window
 }
 
-func NewUIState(desks []WMDesktop) UIState {
+func NewUIState(desks []desktop.Desktop) UIState {
 	st := UIState{
 		Desktops: desks,
 	}
@@ -39,7 +46,98 @@ func NewUIState(desks []WMDesktop) UIState {
 	return st
 }
 
-func (ui *UIState) Desk() *WMDesktop {
+// Update replaces the desktops with a fresh list, keeping selection on
+// the same windows where possible.
+func (ui *UIState) Update(desks []desktop.Desktop) {
+	var curWin xproto.Window
+	curDesk := uint(0)
+	if desk := ui.Desk(); desk != nil {
+		curDesk = desk.Number
+		if len(desk.Windows) > 0 {
+			curWin = desk.Window().XWin
+		}
+	}
+
+	// selected window on each desktop
+	selWin := make(map[uint]xproto.Window)
+	selIdx := make(map[uint]int)
+	for _, desk := range ui.Desktops {
+		if len(desk.Windows) > 0 {
+			selWin[desk.Number] = desk.Window().XWin
+			selIdx[desk.Number] = desk.Selected
+		}
+	}
+
+	ui.Selected = -1
+	for i := range desks {
+		desk := &desks[i]
+		if idx, ok := selIdx[desk.Number]; ok {
+			// window is gone: stay at the same place
+			desk.Selected = idx
+			if desk.Selected >= len(desk.Windows) {
+				desk.Selected = len(desk.Windows) - 1
+			}
+			if desk.Selected < 0 {
+				desk.Selected = 0
+			}
+		}
+		for j, win := range desk.Windows {
+			if win.XWin == selWin[desk.Number] {
+				desk.Selected = j
+			}
+			if curWin != 0 && win.XWin == curWin {
+				// follow the window to another desktop
+				ui.Selected = i
+				desk.Selected = j
+			}
+		}
+	}
+	if ui.Selected < 0 {
+		for i, desk := range desks {
+			if desk.Number == curDesk {
+				ui.Selected = i
+			}
+		}
+	}
+	if ui.Selected < 0 {
+		ui.Selected = 0
+	}
+	ui.Desktops = desks
+
+	// grow to fit, as much as the terminal allows
+	cols, rows := termbox.Size()
+	st := NewUIState(desks)
+	if st.Height > ui.Height {
+		ui.Height = st.Height
+		if ui.Height > rows-4 {
+			ui.Height = rows - 4
+		}
+	}
+	if st.Width > ui.Width {
+		ui.Width = st.Width
+		if ui.Width > cols-2 {
+			ui.Width = cols - 2
+		}
+	}
+}
+
+// SelectRecent selects the most recently focused window that isn't
+// the active one.
+func (ui *UIState) SelectRecent() {
+	for _, xw := range ui.Recent {
+		for i, desk := range ui.Desktops {
+			for j, win := range desk.Windows {
+				if win.XWin == xw && !win.IsActive {
+					ui.Selected = i
+					ui.Desktops[i].Selected = j
+					return
+				}
+			}
+		}
+	}
+}
+
+func (ui *UIState) Desk() *desktop.Desktop {
 	if ui.Selected < 0 {
 		return nil
 	}
@@ -64,12 +162,12 @@ func (ui *UIState) Next() {
 	}
 }
 
-var indexDigits = []rune{'⁰', '¹', '²', '³', '⁴', '⁵', '⁶', '⁷', '⁸', '⁹'}
-
 func (ui *UIState) Draw() {
 	cols, rows := termbox.Size()
-	fgFrame := termbox.ColorYellow
-	fgTitle := termbox.ColorGreen
+	fgFrame := colors["frame"]
+	fgTitle := colors["title"]
+	frame := glyphs["frame"]
+	indexDigits := glyphs["digits"]
 
 	if rows < ui.Height+4 {
 		panic("Too little rows!")
@@ -79,7 +177,7 @@ func (ui *UIState) Draw() {
 	}
 
 	// Tab bar
-	termbox.SetCell(0, 2, '╭', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+	termbox.SetCell(0, 2, frame[0], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 	col := 1
 	for i, desk := range ui.Desktops {
 		if !desk.IsVisible() {
@@ -94,7 +192,7 @@ func (ui *UIState) Draw() {
 		}
 
 		if desk.IsUrgent {
-			fg = termbox.ColorRed
+			fg = colors["urgent"]
 		}
 
 		if desk.IsCurrent {
@@ -106,17 +204,17 @@ func (ui *UIState) Draw() {
 		}
 
 		if i < ui.Selected {
-			termbox.SetCell(col, 0, '╭', fgFrame, termbox.ColorDefault)
-			termbox.SetCell(col, 1, '│', fgFrame, termbox.ColorDefault)
-			termbox.SetCell(col, 2, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[0], fgFrame, termbox.ColorDefault)
+			termbox.SetCell(col, 1, frame[5], fgFrame, termbox.ColorDefault)
+			termbox.SetCell(col, 2, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		} else if i == ui.Selected {
-			termbox.SetCell(col, 0, '╭', fgFrame|termbox.AttrBold, termbox.ColorDefault)
-			termbox.SetCell(col, 1, '│', fgFrame|termbox.AttrBold, termbox.ColorDefault)
-			termbox.SetCell(col, 2, '╯', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[0], fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 1, frame[5], fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 2, frame[3], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		} else {
-			termbox.SetCell(col, 0, '─', fgFrame, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[4], fgFrame, termbox.ColorDefault)
 			termbox.SetCell(col, 1, ' ', fgFrame, termbox.ColorDefault)
-			termbox.SetCell(col, 2, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 2, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		}
 		col++
 
@@ -125,63 +223,63 @@ func (ui *UIState) Draw() {
 			index = indexDigits[i]
 		}
 		if i == ui.Selected {
-			termbox.SetCell(col, 0, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 			termbox.SetCell(col, 1, index, fgFrame, termbox.ColorDefault)
 			termbox.SetCell(col, 2, ' ', fgFrame, termbox.ColorDefault)
 		} else {
-			termbox.SetCell(col, 0, '─', fgFrame, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[4], fgFrame, termbox.ColorDefault)
 			termbox.SetCell(col, 1, index, fgFrame, termbox.ColorDefault)
-			termbox.SetCell(col, 2, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 2, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		}
 		col++
 
 		for _, ch := range desk.Name {
 			termbox.SetCell(col, 1, ch, fg|extra, termbox.ColorDefault)
 			if i == ui.Selected {
-				termbox.SetCell(col, 0, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+				termbox.SetCell(col, 0, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 				termbox.SetCell(col, 2, ' ', fgFrame, termbox.ColorDefault)
 			} else {
-				termbox.SetCell(col, 0, '─', fgFrame, termbox.ColorDefault)
-				termbox.SetCell(col, 2, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+				termbox.SetCell(col, 0, frame[4], fgFrame, termbox.ColorDefault)
+				termbox.SetCell(col, 2, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 			}
 			col++
 		}
 
 		if i == ui.Selected {
-			termbox.SetCell(col, 0, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 			termbox.SetCell(col, 1, ' ', fgFrame, termbox.ColorDefault)
 			termbox.SetCell(col, 2, ' ', fgFrame, termbox.ColorDefault)
 		} else {
-			termbox.SetCell(col, 0, '─', fgFrame, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[4], fgFrame, termbox.ColorDefault)
 			termbox.SetCell(col, 1, ' ', fgFrame, termbox.ColorDefault)
-			termbox.SetCell(col, 2, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 2, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		}
 		col++
 
 		for _, ch := range strconv.Itoa(len(desk.Windows)) {
 			termbox.SetCell(col, 1, ch, fgFrame, termbox.ColorDefault)
 			if i == ui.Selected {
-				termbox.SetCell(col, 0, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+				termbox.SetCell(col, 0, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 				termbox.SetCell(col, 2, ' ', fgFrame, termbox.ColorDefault)
 			} else {
-				termbox.SetCell(col, 0, '─', fgFrame, termbox.ColorDefault)
-				termbox.SetCell(col, 2, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+				termbox.SetCell(col, 0, frame[4], fgFrame, termbox.ColorDefault)
+				termbox.SetCell(col, 2, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 			}
 			col++
 		}
 
 		if i > ui.Selected {
-			termbox.SetCell(col, 0, '╮', fgFrame, termbox.ColorDefault)
-			termbox.SetCell(col, 1, '│', fgFrame, termbox.ColorDefault)
-			termbox.SetCell(col, 2, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[1], fgFrame, termbox.ColorDefault)
+			termbox.SetCell(col, 1, frame[5], fgFrame, termbox.ColorDefault)
+			termbox.SetCell(col, 2, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		} else if i == ui.Selected {
-			termbox.SetCell(col, 0, '╮', fgFrame|termbox.AttrBold, termbox.ColorDefault)
-			termbox.SetCell(col, 1, '│', fgFrame|termbox.AttrBold, termbox.ColorDefault)
-			termbox.SetCell(col, 2, '╰', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[1], fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 1, frame[5], fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 2, frame[2], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		} else {
-			termbox.SetCell(col, 0, '─', fgFrame, termbox.ColorDefault)
+			termbox.SetCell(col, 0, frame[4], fgFrame, termbox.ColorDefault)
 			termbox.SetCell(col, 1, ' ', fgFrame, termbox.ColorDefault)
-			termbox.SetCell(col, 2, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(col, 2, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		}
 		col++
 	}
@@ -191,18 +289,18 @@ func (ui *UIState) Draw() {
 	}
 
 	for ; col < ui.Width+1; col++ {
-		termbox.SetCell(col, 2, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+		termbox.SetCell(col, 2, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 	}
-	termbox.SetCell(ui.Width+1, 2, '╮', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+	termbox.SetCell(ui.Width+1, 2, frame[1], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 
 	// Window List
 	desk := ui.Desk()
 	for i, win := range desk.Windows {
-		fg := termbox.ColorDefault
+		fg := colors["window"]
 		extra := termbox.Attribute(0)
 
 		if win.IsUrgent {
-			fg = termbox.ColorRed
+			fg = colors["urgent"]
 		}
 
 		if win.IsActive {
@@ -213,7 +311,7 @@ func (ui *UIState) Draw() {
 			fg = fg | termbox.AttrReverse
 		}
 
-		termbox.SetCell(0, i+3, '│', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+		termbox.SetCell(0, i+3, frame[5], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		col = 1
 		for _, ch := range win.Name {
 			termbox.SetCell(col, i+3, ch, fg|extra, termbox.ColorDefault)
@@ -223,28 +321,28 @@ func (ui *UIState) Draw() {
 		for ; col < ui.Width+1; col++ {
 			termbox.SetCell(col, i+3, ' ', fg, termbox.ColorDefault)
 		}
-		termbox.SetCell(ui.Width+1, i+3, '│', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+		termbox.SetCell(ui.Width+1, i+3, frame[5], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 	}
 
 	for i := len(desk.Windows); i < ui.Height; i++ {
-		termbox.SetCell(0, i+3, '│', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+		termbox.SetCell(0, i+3, frame[5], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 		for j := 1; j < ui.Width+1; j++ {
 			termbox.SetCell(j, i+3, ' ', termbox.ColorDefault, termbox.ColorDefault)
 		}
-		termbox.SetCell(ui.Width+1, i+3, '│', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+		termbox.SetCell(ui.Width+1, i+3, frame[5], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 	}
 
-	termbox.SetCell(0, ui.Height+3, '╰', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+	termbox.SetCell(0, ui.Height+3, frame[2], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 	for j := 1; j < ui.Width+1; j++ {
-		termbox.SetCell(j, ui.Height+3, '─', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+		termbox.SetCell(j, ui.Height+3, frame[4], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 	}
-	termbox.SetCell(ui.Width+1, ui.Height+3, '╯', fgFrame|termbox.AttrBold, termbox.ColorDefault)
+	termbox.SetCell(ui.Width+1, ui.Height+3, frame[3], fgFrame|termbox.AttrBold, termbox.ColorDefault)
 
 	termbox.Flush()
 }
 
 func (ui *UIState) Main() (erv error) {
-	if fini, err := urxvtermbox.TermboxUrxvt(ui.Width+2, ui.Height+4, "-pe", "destroy_on_focus_out"); err != nil {
+	if fini, err := urxvtermbox.TermboxUrxvt(ui.Width+2, ui.Height+4, urxvtArgs...); err != nil {
 		return err
 	} else {
 		defer func() {
@@ -257,96 +355,99 @@ func (ui *UIState) Main() (erv error) {
 		}()
 	}
 
-	termbox.SetInputMode(termbox.InputEsc)
+	tui.SetInputMode(0)
+
+	if desks := takeUpdate(); desks != nil {
+		// changed while the terminal was starting
+		ui.Update(desks)
+	}
 
 	ui.Draw()
 	for {
-		switch ev := termbox.PollEvent(); ev.Type {
+		switch ev := tui.PollEvent(); ev.Type {
 		case termbox.EventKey:
-			switch ev.Key {
-			case termbox.KeyEsc:
+			switch action := keymap.Action(ev); action {
+			case "cancel":
 				return cmdCancel
-			case termbox.KeyArrowLeft:
+			case "left":
 				ui.Prev()
-			case termbox.KeyArrowRight:
+			case "right":
 				ui.Next()
-			case termbox.KeyArrowUp:
+			case "up":
 				ui.Desk().Prev()
-			case termbox.KeyArrowDown:
+			case "down":
 				ui.Desk().Next()
-			case termbox.KeyTab:
+			case "next-window":
 				ui.Desk().NextWrap()
-			case termbox.KeyEnter:
+			case "choose":
 				return nil
-			case termbox.KeyBackspace, termbox.KeyBackspace2:
+			case "close":
 				return cmdCloseWindow
-			default:
-				switch ev.Ch {
-				case 'w': // ↑
-					ui.Desk().Prev()
-				case 's': // ↓
-					ui.Desk().Next()
-				case 'a': // ←
-					ui.Prev()
-				case 'd': // →
-					ui.Next()
-				case 'q': // Esc
-					return cmdCancel
-				case 'e': // move to active window
-					for i, desk := range ui.Desktops {
-						if desk.IsCurrent {
-							ui.Selected = i
-							for j, win := range desk.Windows {
-								if win.IsActive {
-									// desk is not a pointer
-									ui.Desktops[i].Selected = j
-									break
-								}
+			case "active": // move to active window
+				for i, desk := range ui.Desktops {
+					if desk.IsCurrent {
+						ui.Selected = i
+						for j, win := range desk.Windows {
+							if win.IsActive {
+								// desk is not a pointer
+								ui.Desktops[i].Selected = j
+								break
 							}
-							break
 						}
+						break
 					}
-
-				case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-					if desk := int(ev.Ch - '0'); desk < len(ui.Desktops) && len(ui.Desktops[desk].Windows) > 0 {
-						ui.Selected = desk
-					}
-				case ' ': // same as Enter
-					return nil
-				case '!':
-					// Find next urgent window
-					sxw := ui.Desk().Window().XWin
-					d := ui.Selected
-					w := ui.Desk().Selected
-					for {
-						w++                                   // next window
-						if w >= len(ui.Desktops[d].Windows) { // next desktop
-							w = 0
-							for {
-								d++
-								if d >= len(ui.Desktops) {
-									d = 0
-								}
-								if len(ui.Desktops[d].Windows) > 0 {
-									break
-								}
+				}
+			case "previous":
+				ui.SelectRecent()
+			case "urgent":
+				// Find next urgent window
+				if len(ui.Desk().Windows) == 0 {
+					// emptied by an update
+					break
+				}
+				sxw := ui.Desk().Window().XWin
+				d := ui.Selected
+				w := ui.Desk().Selected
+				for {
+					w++                                   // next window
+					if w >= len(ui.Desktops[d].Windows) { // next desktop
+						w = 0
+						for {
+							d++
+							if d >= len(ui.Desktops) {
+								d = 0
+							}
+							if len(ui.Desktops[d].Windows) > 0 {
+								break
 							}
 						}
-						win := ui.Desktops[d].Windows[w]
-						if win.XWin == sxw {
-							// We have wrapped around, let's break
-							break
-						}
-						if win.IsUrgent {
-							ui.Selected = d
-							ui.Desk().Selected = w
-							break
-						}
+					}
+					win := ui.Desktops[d].Windows[w]
+					if win.XWin == sxw {
+						// We have wrapped around, let's break
+						break
+					}
+					if win.IsUrgent {
+						ui.Selected = d
+						ui.Desk().Selected = w
+						break
+					}
+				}
+			default:
+				if strings.HasPrefix(action, "desktop-") {
+					desk, _ := strconv.Atoi(action[len("desktop-"):])
+					if desk < len(ui.Desktops) && len(ui.Desktops[desk].Windows) > 0 {
+						ui.Selected = desk
 					}
 				}
 			}
 		case termbox.EventInterrupt:
-			return cmdCancel
+			desks := takeUpdate()
+			if desks == nil {
+				// terminal is gone
+				return cmdCancel
+			}
+			ui.Update(desks)
 		case termbox.EventError:
 			return ev.Err
 		default:
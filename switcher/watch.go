@@ -0,0 +1,87 @@
+package switcher
+
+import (
+	"log"
+	"sync"
+
+	"github.com/BurntSushi/xgbutil"
+	"github.com/mpasternacki/termbox-go"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/desktop"
+)
+
+// desktop list that changed while the UI is open, waiting for the UI
+// to pick it up, and whether the UI has been woken up for it
+var (
+	updateMu    sync.Mutex
+	update      []desktop.Desktop
+	interrupted bool
+)
+
+// watch reads the desktop list again whenever it changes, and hands it
+// over to the UI, until stop is called. It listens on its own X
+// connection, so that the session's one doesn't get the events.
+func watch(s *xdwim.Session, desks []desktop.Desktop) (stop func(), err error) {
+	xu, err := xgbutil.NewConnDisplay(s.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := desktop.NewWatcher(xu)
+	if err != nil {
+		xu.Conn().Close()
+		return nil, err
+	}
+	w.Listen(desks)
+
+	go func() {
+		for {
+			ev, xerr := xu.Conn().WaitForEvent()
+			if ev == nil && xerr == nil {
+				// closed by stop
+				return
+			}
+			if xerr != nil || !w.Changed(ev) {
+				continue
+			}
+
+			desks, err := desktop.Get(s.X, s.WM)
+			if err != nil {
+				log.Printf("WARN: desktop.Get: %v", err)
+				continue
+			}
+			w.Listen(desks)
+			postUpdate(desks)
+		}
+	}()
+
+	return func() { xu.Conn().Close() }, nil
+}
+
+// postUpdate wakes the UI up, unless it has been woken up already and
+// didn't take the previous update yet.
+func postUpdate(desks []desktop.Desktop) {
+	updateMu.Lock()
+	update = desks
+	wake := !interrupted && termbox.IsInit
+	if wake {
+		interrupted = true
+	}
+	updateMu.Unlock()
+
+	if wake {
+		termbox.Interrupt()
+	}
+}
+
+// takeUpdate returns the waiting desktop list, or nil if there isn't
+// any: then the interrupt came from elsewhere.
+func takeUpdate() []desktop.Desktop {
+	updateMu.Lock()
+	defer updateMu.Unlock()
+	desks := update
+	update = nil
+	interrupted = false
+	return desks
+}
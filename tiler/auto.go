@@ -0,0 +1,136 @@
+package tiler
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xprop"
+
+	"github.com/mpasternacki/xdwim/daemon"
+	"github.com/mpasternacki/xdwim/desktop"
+)
+
+// X property on the root window with the layout last used on each
+// desktop, as CARDINAL[] of layout index + 1, by desktop number.
+const layoutProp = "_XDWIM_TILER_LAYOUT"
+
+// headWindows returns tileable windows on current desktop that are on
+// the active window's head, the active one first.
+func headWindows() (*desktop.Desktop, []desktop.Window, error) {
+	desks, err := daemon.Desktops(sess)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var desk *desktop.Desktop
+	for i := range desks {
+		if desks[i].IsCurrent {
+			desk = &desks[i]
+		}
+	}
+	if desk == nil {
+		return nil, nil, fmt.Errorf("no current desktop")
+	}
+
+	var wins []desktop.Window
+	for _, win := range desk.Windows {
+		if !wm.IsNormal(win.XWin) {
+			continue
+		}
+
+		geom, err := wm.Geometry(win.XWin)
+		if err != nil {
+			log.Printf("WARN: Geometry(%v): %v", win.XWin, err)
+			continue
+		}
+
+		cx := geom.X() + geom.Width()/2
+		cy := geom.Y() + geom.Height()/2
+		if cx < head.X() || cx >= head.X()+head.Width() ||
+			cy < head.Y() || cy >= head.Y()+head.Height() {
+			continue
+		}
+
+		if win.XWin == axw {
+			wins = append([]desktop.Window{win}, wins...)
+		} else {
+			wins = append(wins, win)
+		}
+	}
+
+	return desk, wins, nil
+}
+
+// lastLayouts returns layoutProp, long enough to index by desk.
+func lastLayouts(desk uint) []uint {
+	last, err := xprop.PropValNums(xprop.GetProperty(xu, xu.RootWin(), layoutProp))
+	if err != nil {
+		last = nil
+	}
+	for uint(len(last)) <= desk {
+		last = append(last, 0)
+	}
+	return last
+}
+
+// autoTile arranges all windows on the active window's head with the
+// named layout. Without a name, it uses the layout that comes after
+// the one used on this desktop last time.
+func autoTile(name string) error {
+	desk, wins, err := headWindows()
+	if err != nil {
+		return err
+	}
+
+	last := lastLayouts(desk.Number)
+
+	li := int(last[desk.Number]) % len(layouts)
+	if name != "" {
+		li = -1
+		for i, l := range layouts {
+			if l.Name == name {
+				li = i
+				break
+			}
+		}
+		if li < 0 {
+			return fmt.Errorf("unknown layout %q", name)
+		}
+	}
+
+	// keep off panels and docks
+	area := workArea(head).rect()
+	rects := layouts[li].Arrange(area, len(wins))
+	for i, win := range wins {
+		xw := win.XWin
+
+		geom, err := wm.Geometry(xw)
+		if err != nil {
+			return err
+		}
+
+		state := wm.PlacementState(xw)
+		pushUndo(xw, geom, state)
+		if len(state) > 0 {
+			if err := wm.SetState(xw, ewmh.StateRemove, state...); err != nil {
+				return err
+			}
+		}
+
+		// same gap as on the grid
+		x, y, w, h := gapRect(rects[i], area)
+		x, y, w, h = fitRectFor(getSizeHints(xu, xw), wm.FrameExtents(xw), alignments[align].alignment, x, y, w, h)
+		if err := wm.MoveResize(xw, x, y, w, h); err != nil {
+			return err
+		}
+	}
+
+	last[desk.Number] = uint(li + 1)
+	err = xprop.ChangeProp32(xu, xu.RootWin(), layoutProp, "CARDINAL", last...)
+	if err != nil {
+		log.Printf("WARN: can't remember layout: %v", err)
+	}
+
+	return ewmh.ActiveWindowReq(xu, axw)
+}
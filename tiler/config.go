@@ -0,0 +1,130 @@
+package tiler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mpasternacki/termbox-go"
+
+	"github.com/mpasternacki/xdwim/config"
+	"github.com/mpasternacki/xdwim/tui"
+)
+
+var defaultKeys = map[string][]string{
+	"cancel":      {"esc"},
+	"up":          {"up", "w"},
+	"down":        {"down", "s"},
+	"left":        {"left", "a"},
+	"right":       {"right", "d"},
+	"top":         {"W"},
+	"bottom":      {"S"},
+	"leftmost":    {"A"},
+	"rightmost":   {"D"},
+	"choose":      {"enter"},
+	"mark":        {"space"},
+	"corner":      {"tab"},
+	"unmark":      {"backspace", "q"},
+	"original":    {"e"},
+	"full-width":  {"h"},
+	"full-height": {"v"},
+	"column":      {"x"},
+	"row":         {"y"},
+	"maximize":    {"m"},
+	"max-horz":    {"H"},
+	"max-vert":    {"V"},
+	"fullscreen":  {"f"},
+	"free":        {"g"},
+	"grow":        {">"},
+	"shrink":      {"<"},
+	"move":        {"M"},
+	"undo":        {"u"},
+	"next-preset": {"p"},
+	"prev-preset": {"P"},
+	"align":       {"c"},
+	"next-window": {"n"},
+
+	// nudge; it moves with up, down, left and right
+	"grow-up":      {"shift-up"},
+	"grow-down":    {"shift-down"},
+	"grow-left":    {"shift-left"},
+	"grow-right":   {"shift-right"},
+	"shrink-up":    {"ctrl-up"},
+	"shrink-down":  {"ctrl-down"},
+	"shrink-left":  {"ctrl-left"},
+	"shrink-right": {"ctrl-right"},
+
+	// pointer mode
+	"click-middle": {"i"},
+	"click-right":  {"o"},
+	"warp":         {"t"},
+
+	"prefix-10": {"0"},
+	"prefix-11": {"-"},
+	"prefix-12": {"="},
+}
+
+func init() {
+	for i := 1; i < 10; i++ {
+		defaultKeys["prefix-"+strconv.Itoa(i)] = []string{strconv.Itoa(i)}
+	}
+}
+
+var defaultColors = map[string]termbox.Attribute{
+	"axis":         termbox.ColorDefault,
+	"axis-current": termbox.ColorWhite | termbox.AttrBold,
+	"grid":         termbox.ColorBlue,
+	"neighbour":    termbox.ColorBlack,
+	"original":     termbox.ColorGreen,
+	"swap":         termbox.ColorMagenta,
+	"cursor":       termbox.ColorYellow,
+	"prefix":       termbox.ColorGreen | termbox.AttrBold,
+	"no-prefix":    termbox.ColorBlack | termbox.AttrBold,
+	"pending":      termbox.ColorRed | termbox.AttrBold,
+	"align":        termbox.ColorYellow | termbox.AttrBold,
+	"size":         termbox.ColorDefault,
+	"warning":      termbox.ColorRed,
+	"preset":       termbox.ColorCyan,
+	"label":        termbox.ColorBlack | termbox.AttrBold,
+	"region-1":     termbox.ColorMagenta,
+	"region-2":     termbox.ColorCyan,
+	"region-3":     termbox.ColorRed,
+	"region-4":     termbox.ColorWhite,
+	"region-5":     termbox.ColorYellow,
+	"step":         termbox.ColorYellow,
+	"count":        termbox.ColorGreen | termbox.AttrBold,
+	"help":         termbox.ColorBlack | termbox.AttrBold,
+}
+
+var defaultGlyphs = map[string]string{
+	"grid":      "░",
+	"cursor":    "█",
+	"selection": "▓",
+	"region":    "▒",
+}
+
+var (
+	keymap    tui.Keymap
+	colors    map[string]termbox.Attribute
+	glyphs    map[string][]rune
+	urxvtArgs []string
+)
+
+// configure sets up keys, colours and glyphs from the [tiler] section
+// of the config file.
+func configure(cfg config.Tiler) error {
+	var err error
+	if keymap, err = tui.NewKeymap(defaultKeys, cfg.Keys); err != nil {
+		return fmt.Errorf("%s: [tiler.keys]: %v", config.Path(), err)
+	}
+	if colors, err = tui.Colors(defaultColors, cfg.Colors); err != nil {
+		return fmt.Errorf("%s: [tiler.colors]: %v", config.Path(), err)
+	}
+	if glyphs, err = tui.Glyphs(defaultGlyphs, cfg.Glyphs); err != nil {
+		return fmt.Errorf("%s: [tiler.glyphs]: %v", config.Path(), err)
+	}
+	urxvtArgs = []string{"-pe", "destroy_on_focus_out"}
+	if cfg.KeepOpen {
+		urxvtArgs = nil
+	}
+	return nil
+}
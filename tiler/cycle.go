@@ -0,0 +1,128 @@
+package tiler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgbutil/xprop"
+)
+
+// X property on the client remembering the last cycle step applied to
+// it, as "name index".
+const cycleProp = "_XDWIM_TILER_CYCLE"
+
+// cycle is a list of presets that repeated applying steps through.
+type cycle struct {
+	Name  string
+	Steps []preset
+}
+
+var defaultCycles = map[string][]string{
+	"left":   {"left-half", "left-two-thirds", "left-third"},
+	"right":  {"right-half", "right-two-thirds", "right-third"},
+	"top":    {"top-half", "top-left", "top-right"},
+	"bottom": {"bottom-half", "bottom-left", "bottom-right"},
+	"center": {"center", "center-third", "full"},
+}
+
+// loadCycles merges cycles from config file into the defaults and
+// resolves their steps.
+func loadCycles(conf map[string][]string, presets []preset) ([]cycle, error) {
+	merged := make(map[string][]string)
+	for name, steps := range defaultCycles {
+		merged[name] = steps
+	}
+	for name, steps := range conf {
+		merged[name] = steps
+	}
+
+	names := make([]string, 0, len(merged))
+	for name := range merged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cycles := make([]cycle, 0, len(names))
+	for _, name := range names {
+		if len(merged[name]) == 0 {
+			return nil, fmt.Errorf("cycle %s: no steps", name)
+		}
+
+		c := cycle{Name: name}
+		for _, spec := range merged[name] {
+			p, err := findPreset(presets, spec)
+			if err != nil {
+				return nil, fmt.Errorf("cycle %s: %v", name, err)
+			}
+			c.Steps = append(c.Steps, p)
+		}
+		cycles = append(cycles, c)
+	}
+
+	return cycles, nil
+}
+
+func (c cycle) String() string {
+	names := make([]string, len(c.Steps))
+	for i, p := range c.Steps {
+		names[i] = p.Name
+	}
+	return fmt.Sprintf("%s: %s", c.Name, strings.Join(names, " → "))
+}
+
+// lastCycleStep returns the cycle name and step last applied to the
+// active window, or "", -1.
+func lastCycleStep() (string, int) {
+	val, err := xprop.PropValStr(xprop.GetProperty(xu, axw, cycleProp))
+	if err != nil {
+		return "", -1
+	}
+
+	fields := strings.Fields(val)
+	if len(fields) != 2 {
+		return "", -1
+	}
+
+	step, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", -1
+	}
+
+	return fields[0], step
+}
+
+// applyCycle moves the active window to the first step of a cycle, or
+// to the next step if the previous tiler run already applied this
+// cycle to it.
+func applyCycle(name string) error {
+	var c *cycle
+	for i := range cycles {
+		if cycles[i].Name == name {
+			c = &cycles[i]
+			break
+		}
+	}
+	if c == nil {
+		return fmt.Errorf("unknown cycle %q", name)
+	}
+
+	step := 0
+	if last, lastStep := lastCycleStep(); last == name {
+		step = (lastStep + 1) % len(c.Steps)
+	}
+
+	if err := apply(c.Steps[step]); err != nil {
+		return err
+	}
+
+	// place() forgets the cycle; remember it after it's done
+	err := xprop.ChangeProp(xu, axw, 8, cycleProp, "UTF8_STRING",
+		[]byte(fmt.Sprintf("%s %d", name, step)))
+	if err != nil {
+		log.Printf("WARN: can't remember cycle step: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package tiler
+
+import "testing"
+
+func TestLoadCycles(t *testing.T) {
+	cycles, err := loadCycles(map[string][]string{
+		"left": {"left-third", "1,0,4,12"},
+		"wide": {"full", "center"},
+	}, defaultPresets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"left":   "left: left-third → 1,0,4,12",
+		"right":  "right: right-half → right-two-thirds → right-third",
+		"top":    "top: top-half → top-left → top-right",
+		"bottom": "bottom: bottom-half → bottom-left → bottom-right",
+		"center": "center: center → center-third → full",
+		"wide":   "wide: full → center",
+	}
+	if len(cycles) != len(want) {
+		t.Errorf("got %d cycles; want %d", len(cycles), len(want))
+	}
+	for i, c := range cycles {
+		if i > 0 && cycles[i-1].Name >= c.Name {
+			t.Errorf("cycles not sorted: %s before %s", cycles[i-1].Name, c.Name)
+		}
+		if s := c.String(); s != want[c.Name] {
+			t.Errorf("cycle %s = %q; want %q", c.Name, s, want[c.Name])
+		}
+	}
+	if step := cycles[2].Steps[1]; step != (preset{"1,0,4,12", 1, 0, 4, 12}) {
+		t.Errorf("left step 1 = %v", step)
+	}
+
+	for _, conf := range []map[string][]string{
+		{"empty": {}},
+		{"typo": {"left-hlaf"}},
+		{"off-grid": {"0,0,13,12"}},
+	} {
+		if _, err := loadCycles(conf, defaultPresets); err == nil {
+			t.Errorf("loadCycles(%v): no error", conf)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package tiler
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/config"
+)
+
+// LoadPresets reads presets from the config, for Tile and CheckPreset.
+func LoadPresets(cfg config.Tiler) error {
+	var err error
+	presets, err = loadPresets(cfg.Presets)
+	return err
+}
+
+// CheckPreset tells whether spec is a known preset or a valid grid
+// rectangle.
+func CheckPreset(spec string) error {
+	_, err := findPreset(presets, spec)
+	return err
+}
+
+// Tile moves the window to a preset or grid rectangle on the Nth head
+// (1-based), or on the one it's on if n is 0. Unlike "xdwim tile
+// apply", it doesn't focus the window.
+func Tile(s *xdwim.Session, xw xproto.Window, n int, spec string) error {
+	begin(s)
+
+	p, err := findPreset(presets, spec)
+	if err != nil {
+		return err
+	}
+
+	axw = xw
+	if err := setupWindow(); err != nil {
+		return err
+	}
+
+	if n > 0 {
+		heads, err := s.Heads()
+		if err != nil {
+			return err
+		}
+		if n > len(heads) {
+			return fmt.Errorf("no head %d, there are %d", n, len(heads))
+		}
+		head = heads[n-1]
+	}
+
+	return moveWindow(fitRect(cellRect(p.cells())))
+}
+
+// Move moves the window to the outer geometry, clearing its maximized
+// and fullscreen states, and remembers where it was for undo.
+func Move(s *xdwim.Session, xw xproto.Window, x, y, w, h int) error {
+	begin(s)
+
+	axw = xw
+	if err := setupWindow(); err != nil {
+		return err
+	}
+
+	return moveWindow(x, y, w, h)
+}
+
+// moveWindow is place, without focusing the window.
+func moveWindow(x, y, w, h int) error {
+	if len(origState) > 0 {
+		err := wm.SetState(axw, ewmh.StateRemove, origState...)
+		if err != nil {
+			return err
+		}
+	}
+
+	pushUndo(axw, origGeom, origState)
+	return wm.MoveResize(axw, x, y, w, h)
+}
@@ -0,0 +1,351 @@
+package tiler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/keybind"
+	"github.com/BurntSushi/xgbutil/xrect"
+	"github.com/BurntSushi/xgbutil/xwindow"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/daemon"
+)
+
+// ExposeCommand is the "expose" command of xdwim.
+var ExposeCommand = xdwim.Command{
+	Name:     "expose",
+	Synopsis: "show all windows side by side and pick one",
+	Run:      RunExpose,
+	Usage:    exposeUsage,
+}
+
+func exposeUsage(prog string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s\n", prog)
+}
+
+const (
+	exposeFont  = "fixed"
+	exposeLabel = 48 // longest label, in characters
+	// more windows than hints for them are left alone
+	maxExposed = 26 * 26
+)
+
+// exposed is a window laid out for picking, with what's needed to put
+// it back.
+type exposed struct {
+	xw    xproto.Window
+	geom  xrect.Rect
+	state []string
+	key   string
+	label *xwindow.Window
+	text  string
+}
+
+// RunExpose tiles all windows on the current desktop in a grid on
+// their heads, labels each with a key, and activates the window whose
+// key is pressed. Windows go back where they were either way.
+func RunExpose(s *xdwim.Session, args []string) error {
+	begin(s)
+	if len(args) > 0 {
+		return xdwim.ErrUsage
+	}
+
+	wins, err := exposeWindows()
+	if err != nil {
+		return err
+	}
+	if len(wins) == 0 {
+		return nil
+	}
+
+	defer func() {
+		for _, win := range wins {
+			if win.label != nil {
+				win.label.Destroy()
+			}
+			x, y, w, h := win.geom.Pieces()
+			if err := wm.MoveResize(win.xw, x, y, w, h); err != nil {
+				log.Printf("WARN: can't put %v back: %v", win.xw, err)
+			}
+			if len(win.state) > 0 {
+				if err := wm.SetState(win.xw, ewmh.StateAdd, win.state...); err != nil {
+					log.Printf("WARN: can't restore state of %v: %v", win.xw, err)
+				}
+			}
+		}
+		xu.Sync()
+	}()
+
+	if err := exposeLayout(wins); err != nil {
+		return err
+	}
+
+	xw, err := exposePick(wins)
+	if err != nil || xw == 0 {
+		return err
+	}
+	return ewmh.ActiveWindowReq(xu, xw)
+}
+
+// exposeWindows lists the windows on the current desktop, with keys
+// to pick them by.
+func exposeWindows() ([]*exposed, error) {
+	desks, err := daemon.Desktops(sess)
+	if err != nil {
+		return nil, err
+	}
+
+	var wins []*exposed
+	var names []string
+	for _, desk := range desks {
+		if !desk.IsCurrent {
+			continue
+		}
+		for _, win := range desk.Windows {
+			if !wm.IsNormal(win.XWin) {
+				continue
+			}
+			geom, err := wm.Geometry(win.XWin)
+			if err != nil {
+				log.Printf("WARN: Geometry(%v): %v", win.XWin, err)
+				continue
+			}
+
+			if len(wins) == maxExposed {
+				log.Printf("WARN: more than %d windows, leaving %v alone", maxExposed, win.XWin)
+				continue
+			}
+			wins = append(wins, &exposed{
+				xw:    win.XWin,
+				geom:  geom,
+				state: wm.PlacementState(win.XWin),
+			})
+			names = append(names, win.Name)
+		}
+	}
+
+	for i, key := range hintKeys(len(wins)) {
+		wins[i].key = key
+		wins[i].text = labelText(key + " " + names[i])
+	}
+	return wins, nil
+}
+
+// hintKeys returns n keys to pick windows with: single letters if
+// there are few enough windows, pairs of letters if not.
+func hintKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		if n <= 26 {
+			keys[i] = string(rune('a' + i))
+		} else {
+			keys[i] = string([]rune{rune('a' + i/26), rune('a' + i%26)})
+		}
+	}
+	return keys
+}
+
+// labelText makes text fit for a label: ImageText8 draws bytes, and
+// core fonts can't be relied on for more than ASCII, so anything else
+// becomes '?'. Labels also shouldn't get too wide.
+func labelText(text string) string {
+	var buf []byte
+	for _, r := range text {
+		if r < ' ' || r > '~' {
+			r = '?'
+		}
+		buf = append(buf, byte(r))
+	}
+	if len(buf) > exposeLabel {
+		buf = append(buf[:exposeLabel-3], "..."...)
+	}
+	return string(buf)
+}
+
+// exposeLayout puts windows in an even grid on the head each is on,
+// and labels them.
+func exposeLayout(wins []*exposed) error {
+	heads, err := sess.Heads()
+	if err != nil {
+		return err
+	}
+
+	onHead := make([][]*exposed, len(heads))
+	for _, win := range wins {
+		i := xdwim.HeadIndex(heads, win.geom)
+		onHead[i] = append(onHead[i], win)
+	}
+
+	// maximized and fullscreen windows ignore moves, or the WM puts
+	// them back
+	for _, win := range wins {
+		if len(win.state) > 0 {
+			if err := wm.SetState(win.xw, ewmh.StateRemove, win.state...); err != nil {
+				return err
+			}
+		}
+	}
+	waitUnplaced(wins)
+
+	// the last alignment is the centered one
+	center := alignments[len(alignments)-1].alignment
+	for i, hwins := range onHead {
+		area := workArea(heads[i]).rect()
+		rects := evenGrid(area, len(hwins))
+		for j, win := range hwins {
+			// same gap as on the grid
+			x, y, w, h := gapRect(rects[j], area)
+			x, y, w, h = fitRectFor(getSizeHints(xu, win.xw), wm.FrameExtents(win.xw), center, x, y, w, h)
+			if err := wm.MoveResize(win.xw, x, y, w, h); err != nil {
+				return err
+			}
+
+			if win.label, err = newLabel(x+w/2, y+h/2, win.text); err != nil {
+				return err
+			}
+		}
+	}
+	xu.Sync()
+	return nil
+}
+
+// waitUnplaced waits a moment for the WM to drop windows' placement
+// states; SetState only asks it to.
+func waitUnplaced(wins []*exposed) {
+	for i := 0; i < 50; i++ {
+		done := true
+		for _, win := range wins {
+			if len(win.state) > 0 && len(wm.PlacementState(win.xw)) > 0 {
+				done = false
+				break
+			}
+		}
+		if done {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	log.Printf("WARN: WM didn't unmaximize all windows")
+}
+
+// newLabel shows an override-redirect window with text, centered on
+// (x, y). Text is drawn on Expose by exposePick.
+func newLabel(x, y int, text string) (*xwindow.Window, error) {
+	// the fixed font is 6x13
+	w, h := 6*len(text)+8, 19
+	win, err := xwindow.Generate(xu)
+	if err != nil {
+		return nil, err
+	}
+	err = win.CreateChecked(xu.RootWin(), x-w/2, y-h/2, w, h,
+		xproto.CwBackPixel|xproto.CwOverrideRedirect|xproto.CwEventMask,
+		previewColor, 1, xproto.EventMaskExposure)
+	if err != nil {
+		return nil, err
+	}
+	win.Map()
+	win.Stack(xproto.StackModeAbove)
+	return win, nil
+}
+
+// exposePick grabs the keyboard and waits for a window's key. It
+// returns 0 if the user pressed anything else.
+func exposePick(wins []*exposed) (xproto.Window, error) {
+	c := xu.Conn()
+
+	font, err := xproto.NewFontId(c)
+	if err != nil {
+		return 0, err
+	}
+	if err := xproto.OpenFontChecked(c, font, uint16(len(exposeFont)), exposeFont).Check(); err != nil {
+		return 0, err
+	}
+	defer xproto.CloseFont(c, font)
+
+	gc, err := xproto.NewGcontextId(c)
+	if err != nil {
+		return 0, err
+	}
+	xproto.CreateGC(c, gc, xproto.Drawable(xu.RootWin()),
+		xproto.GcForeground|xproto.GcBackground|xproto.GcFont,
+		[]uint32{xu.Screen().BlackPixel, previewColor, uint32(font)})
+	defer xproto.FreeGC(c, gc)
+
+	// the key that started us may still be held, and the WM may have
+	// the keyboard grabbed until it's released
+	grabbed := false
+	for i := 0; i < 10 && !grabbed; i++ {
+		reply, err := xproto.GrabKeyboard(c, false, xu.RootWin(), xproto.TimeCurrentTime,
+			xproto.GrabModeAsync, xproto.GrabModeAsync).Reply()
+		if err != nil {
+			return 0, err
+		}
+		grabbed = reply.Status == xproto.GrabStatusSuccess
+		if !grabbed {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	if !grabbed {
+		return 0, errors.New("can't grab the keyboard")
+	}
+	defer xproto.UngrabKeyboard(c, xproto.TimeCurrentTime)
+
+	keybind.Initialize(xu)
+	typed := ""
+	for {
+		ev, xerr := c.WaitForEvent()
+		if ev == nil && xerr == nil {
+			return 0, errors.New("X connection closed")
+		}
+		if xerr != nil {
+			log.Printf("WARN: %v", xerr)
+			continue
+		}
+
+		switch ev := ev.(type) {
+		case xproto.ExposeEvent:
+			for _, win := range wins {
+				if win.label != nil && win.label.Id == ev.Window {
+					xproto.ImageText8(c, byte(len(win.text)), xproto.Drawable(ev.Window), gc,
+						4, 14, win.text)
+				}
+			}
+		case xproto.KeyPressEvent:
+			key := keybind.LookupString(xu, ev.State, ev.Detail)
+			if key == "" || isModifier(key) {
+				continue
+			}
+			typed += key
+			prefix := false
+			for _, win := range wins {
+				if win.key == typed {
+					return win.xw, nil
+				}
+				if strings.HasPrefix(win.key, typed) {
+					prefix = true
+				}
+			}
+			if !prefix {
+				return 0, nil
+			}
+		}
+	}
+}
+
+// isModifier tells whether keysym name is a modifier key, which
+// shouldn't cancel picking on its own.
+func isModifier(key string) bool {
+	for _, mod := range []string{"Shift", "Control", "Alt", "Meta", "Super", "Hyper", "ISO_Level", "Caps_Lock", "Num_Lock"} {
+		if strings.HasPrefix(key, mod) {
+			return true
+		}
+	}
+	return false
+}
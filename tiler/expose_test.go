@@ -0,0 +1,57 @@
+package tiler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHintKeys(t *testing.T) {
+	for _, tc := range []struct {
+		n     int
+		first string
+		last  string
+	}{
+		{1, "a", "a"},
+		{26, "a", "z"},
+		{27, "aa", "ba"},
+		{52, "aa", "bz"},
+		{maxExposed, "aa", "zz"},
+	} {
+		keys := hintKeys(tc.n)
+		if len(keys) != tc.n || keys[0] != tc.first || keys[len(keys)-1] != tc.last {
+			t.Errorf("hintKeys(%d) = %d keys, %s to %s; want %s to %s",
+				tc.n, len(keys), keys[0], keys[len(keys)-1], tc.first, tc.last)
+		}
+
+		// typing a key must not go through another one on the way
+		seen := make(map[string]bool)
+		for _, k := range keys {
+			if seen[k] {
+				t.Errorf("hintKeys(%d): %s twice", tc.n, k)
+			}
+			seen[k] = true
+		}
+		for _, k := range keys {
+			for i := 1; i < len(k); i++ {
+				if seen[k[:i]] {
+					t.Errorf("hintKeys(%d): %s is a prefix of %s", tc.n, k[:i], k)
+				}
+			}
+		}
+	}
+}
+
+func TestLabelText(t *testing.T) {
+	for _, tc := range []struct {
+		text, want string
+	}{
+		{"a urxvt", "a urxvt"},
+		{"b Zażółć — Firefox", "b Za???? ? Firefox"},
+		{"c tab\there", "c tab?here"},
+		{"d " + strings.Repeat("x", 60), "d " + strings.Repeat("x", exposeLabel-5) + "..."},
+	} {
+		if got := labelText(tc.text); got != tc.want {
+			t.Errorf("labelText(%q) = %q; want %q", tc.text, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package tiler
+
+const gridSize = 12
+
+// cellGap is the space between windows in adjacent cells. There's none
+// on the edges, so a full-width selection is exactly as wide as the
+// head.
+const cellGap = 4
+
+// gridEdge returns offset of the i-th of the gridSize+1 lines that
+// divide length pixels into cells. Remainder pixels are spread over
+// the cells instead of piling up at the end, so adjacent cells share
+// an edge and the last line is exactly at length.
+func gridEdge(length, i int) int {
+	return split(length, i, gridSize)
+}
+
+// split returns offset of the i-th of n+1 lines that divide length
+// pixels into n parts as equal as possible.
+func split(length, i, n int) int {
+	return length * i / n
+}
+
+// gridLine returns the grid line nearest to pixel offset px.
+func gridLine(length, px int) int {
+	i := (2*px*gridSize + length) / (2 * length)
+	if i < 0 {
+		return 0
+	}
+	if i > gridSize {
+		return gridSize
+	}
+	return i
+}
+
+// gridSpan returns the pixel offset and size of cells i0 to i1,
+// inclusive.
+func gridSpan(length, i0, i1 int) (int, int) {
+	if i0 > i1 {
+		i0, i1 = i1, i0
+	}
+	off := gridEdge(length, i0)
+	return off, gridEdge(length, i1+1) - off
+}
+
+// gridCells returns the first and last cell covered by pixels from px0
+// to px1, with both ends rounded to the nearest grid line. At least
+// one cell is always covered.
+func gridCells(length, px0, px1 int) (int, int) {
+	i0, i1 := gridLine(length, px0), gridLine(length, px1)-1
+	if i0 > gridSize-1 {
+		i0 = gridSize - 1
+	}
+	if i1 < i0 {
+		i1 = i0
+	}
+	return i0, i1
+}
+
+// gapSpan takes half of cellGap off each end of the span from off to
+// off+size that is not on an edge of length.
+func gapSpan(length, off, size int) (int, int) {
+	if off > 0 {
+		off += cellGap / 2
+		size -= cellGap / 2
+	}
+	if off+size < length {
+		size -= cellGap / 2
+	}
+	return off, size
+}
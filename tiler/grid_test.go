@@ -0,0 +1,76 @@
+package tiler
+
+import "testing"
+
+// widths and heights of common screens
+var gridLengths = []int{1080, 1200, 1366, 1440, 1600, 1920, 2160, 2560, 3840}
+
+func TestGridSpansShareEdges(t *testing.T) {
+	for _, length := range gridLengths {
+		end := 0
+		for i := 0; i < gridSize; i++ {
+			off, size := gridSpan(length, i, i)
+			if off != end {
+				t.Errorf("length %d: cell %d starts at %d, previous ends at %d", length, i, off, end)
+			}
+			if size <= 0 {
+				t.Errorf("length %d: cell %d has size %d", length, i, size)
+			}
+			end = off + size
+		}
+		if end != length {
+			t.Errorf("length %d: last cell ends at %d", length, end)
+		}
+		if last := gridEdge(length, gridSize); last != length {
+			t.Errorf("length %d: last edge at %d", length, last)
+		}
+	}
+}
+
+func TestGridCellsRoundTrip(t *testing.T) {
+	for _, length := range gridLengths {
+		for i0 := 0; i0 < gridSize; i0++ {
+			for i1 := i0; i1 < gridSize; i1++ {
+				off, size := gridSpan(length, i0, i1)
+				if c0, c1 := gridCells(length, off, off+size); c0 != i0 || c1 != i1 {
+					t.Errorf("length %d: cells %d-%d → %d+%d → cells %d-%d",
+						length, i0, i1, off, size, c0, c1)
+				}
+			}
+		}
+	}
+}
+
+func TestGapSpan(t *testing.T) {
+	for _, tc := range []struct {
+		off, size       int
+		wantOff, wantSz int
+	}{
+		{0, 1920, 0, 1920},     // full width touches both edges
+		{0, 960, 0, 958},       // left half
+		{960, 960, 962, 958},   // right half
+		{640, 640, 642, 636},   // middle third
+		{1918, 2, 1920, 0},     // sliver at the edge
+		{480, 1440, 482, 1438}, // right three quarters
+	} {
+		off, size := gapSpan(1920, tc.off, tc.size)
+		if off != tc.wantOff || size != tc.wantSz {
+			t.Errorf("gapSpan(1920, %d, %d) = %d, %d; want %d, %d",
+				tc.off, tc.size, off, size, tc.wantOff, tc.wantSz)
+		}
+	}
+}
+
+func TestGapSpanAdjacent(t *testing.T) {
+	for _, length := range gridLengths {
+		for i := 0; i+1 < gridSize; i++ {
+			off0, size0 := gridSpan(length, i, i)
+			off0, size0 = gapSpan(length, off0, size0)
+			off1, size1 := gridSpan(length, i+1, i+1)
+			off1, _ = gapSpan(length, off1, size1)
+			if gap := off1 - (off0 + size0); gap != cellGap {
+				t.Errorf("length %d: cells %d and %d are %d apart", length, i, i+1, gap)
+			}
+		}
+	}
+}
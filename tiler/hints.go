@@ -0,0 +1,109 @@
+package tiler
+
+import (
+	"log"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/icccm"
+
+	"github.com/mpasternacki/xdwim/netwm"
+)
+
+// sizeHints is the part of WM_NORMAL_HINTS that limits which client
+// sizes are legal. Zero values mean no constraint.
+type sizeHints struct {
+	baseW, baseH int
+	incW, incH   int
+	minW, minH   int
+	maxW, maxH   int
+}
+
+func getSizeHints(xu *xgbutil.XUtil, win xproto.Window) sizeHints {
+	var sh sizeHints
+
+	nh, err := icccm.WmNormalHintsGet(xu, win)
+	if err != nil {
+		if !netwm.NoProperty(err) {
+			log.Printf("WARN: WmNormalHintsGet(%v): %v", win, err)
+		}
+		return sh
+	}
+
+	if nh.Flags&icccm.SizeHintPMinSize != 0 {
+		sh.minW, sh.minH = int(nh.MinWidth), int(nh.MinHeight)
+	}
+	if nh.Flags&icccm.SizeHintPMaxSize != 0 {
+		sh.maxW, sh.maxH = int(nh.MaxWidth), int(nh.MaxHeight)
+	}
+	if nh.Flags&icccm.SizeHintPResizeInc != 0 {
+		sh.incW, sh.incH = int(nh.WidthInc), int(nh.HeightInc)
+	}
+
+	// ICCCM: base size defaults to min size and vice versa
+	if nh.Flags&icccm.SizeHintPBaseSize != 0 {
+		sh.baseW, sh.baseH = int(nh.BaseWidth), int(nh.BaseHeight)
+		if nh.Flags&icccm.SizeHintPMinSize == 0 {
+			sh.minW, sh.minH = sh.baseW, sh.baseH
+		}
+	} else {
+		sh.baseW, sh.baseH = sh.minW, sh.minH
+	}
+
+	return sh
+}
+
+func fitDim(size, base, inc, min, max int) int {
+	if inc > 1 && size > base {
+		size = base + (size-base)/inc*inc
+	}
+	if max > 0 && size > max {
+		size = max
+	}
+	if size < min {
+		size = min
+	}
+	return size
+}
+
+// fit returns the legal client size nearest to w×h that doesn't exceed
+// it, unless the minimum size does.
+func (sh sizeHints) fit(w, h int) (int, int) {
+	return fitDim(w, sh.baseW, sh.incW, sh.minW, sh.maxW),
+		fitDim(h, sh.baseH, sh.incH, sh.minH, sh.maxH)
+}
+
+// Alignment of a window that can't fill its cell, as a gravity: -1
+// sticks to the left/top edge, 0 centers, 1 sticks to right/bottom.
+type alignment struct{ x, y int }
+
+// order in which the align key cycles through them, with their glyphs
+var alignments = []struct {
+	alignment
+	glyph rune
+}{
+	{alignment{-1, -1}, '↖'},
+	{alignment{0, -1}, '↑'},
+	{alignment{1, -1}, '↗'},
+	{alignment{1, 0}, '→'},
+	{alignment{1, 1}, '↘'},
+	{alignment{0, 1}, '↓'},
+	{alignment{-1, 1}, '↙'},
+	{alignment{-1, 0}, '←'},
+	{alignment{0, 0}, '·'},
+}
+
+func alignDim(pos, size, want int, align int) int {
+	switch {
+	case align < 0:
+		return pos
+	case align > 0:
+		return pos + size - want
+	default:
+		return pos + (size-want)/2
+	}
+}
+
+func (al alignment) place(x, y, w, h, fw, fh int) (int, int) {
+	return alignDim(x, w, fw, al.x), alignDim(y, h, fh, al.y)
+}
@@ -0,0 +1,47 @@
+package tiler
+
+import "testing"
+
+func TestSizeHintsFit(t *testing.T) {
+	// a terminal: 6×13 cells, 4px border, at least 10×3 cells
+	term := sizeHints{baseW: 4, baseH: 4, incW: 6, incH: 13, minW: 64, minH: 43}
+
+	for _, tc := range []struct {
+		name         string
+		sh           sizeHints
+		w, h         int
+		wantW, wantH int
+	}{
+		{"no hints", sizeHints{}, 955, 1075, 955, 1075},
+		{"increments round down", term, 955, 1075, 952, 1070},
+		{"exact fit", term, 952, 1070, 952, 1070},
+		{"minimum wins", term, 20, 20, 64, 43},
+		{"maximum", sizeHints{maxW: 800, maxH: 600}, 955, 1075, 800, 600},
+		{"increment of one", sizeHints{incW: 1, incH: 1}, 955, 1075, 955, 1075},
+		{"below base", sizeHints{baseW: 100, incW: 10}, 50, 50, 50, 50},
+	} {
+		w, h := tc.sh.fit(tc.w, tc.h)
+		if w != tc.wantW || h != tc.wantH {
+			t.Errorf("%s: fit(%d, %d) = %d, %d; want %d, %d",
+				tc.name, tc.w, tc.h, w, h, tc.wantW, tc.wantH)
+		}
+	}
+}
+
+func TestAlignmentPlace(t *testing.T) {
+	for _, tc := range []struct {
+		al           alignment
+		wantX, wantY int
+	}{
+		{alignment{-1, -1}, 100, 200},
+		{alignment{0, 0}, 140, 220},
+		{alignment{1, 1}, 180, 240},
+		{alignment{1, -1}, 180, 200},
+	} {
+		// 80×40 window in a 160×80 cell at 100,200
+		x, y := tc.al.place(100, 200, 160, 80, 80, 40)
+		if x != tc.wantX || y != tc.wantY {
+			t.Errorf("%v: place = %d, %d; want %d, %d", tc.al, x, y, tc.wantX, tc.wantY)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package tiler
+
+import (
+	"math"
+
+	"github.com/BurntSushi/xgbutil/xrect"
+)
+
+// layout arranges n windows within area. First window is the master
+// one, if the layout has such thing.
+type layout struct {
+	Name    string
+	Arrange func(area xrect.Rect, n int) []xrect.Rect
+}
+
+// in the order tiler auto cycles through them
+var layouts = []layout{
+	{"master-stack", masterStack},
+	{"columns", columns},
+	{"rows", rows},
+	{"grid", evenGrid},
+	{"spiral", spiral},
+}
+
+// sliceX returns i-th of n equal vertical slices of r.
+func sliceX(r xrect.Rect, i, n int) xrect.Rect {
+	x0, x1 := split(r.Width(), i, n), split(r.Width(), i+1, n)
+	return xrect.New(r.X()+x0, r.Y(), x1-x0, r.Height())
+}
+
+// sliceY returns i-th of n equal horizontal slices of r.
+func sliceY(r xrect.Rect, i, n int) xrect.Rect {
+	y0, y1 := split(r.Height(), i, n), split(r.Height(), i+1, n)
+	return xrect.New(r.X(), r.Y()+y0, r.Width(), y1-y0)
+}
+
+func columns(area xrect.Rect, n int) []xrect.Rect {
+	rv := make([]xrect.Rect, n)
+	for i := range rv {
+		rv[i] = sliceX(area, i, n)
+	}
+	return rv
+}
+
+func rows(area xrect.Rect, n int) []xrect.Rect {
+	rv := make([]xrect.Rect, n)
+	for i := range rv {
+		rv[i] = sliceY(area, i, n)
+	}
+	return rv
+}
+
+// masterStack puts the first window in the left half and stacks the
+// others in the right one.
+func masterStack(area xrect.Rect, n int) []xrect.Rect {
+	if n < 2 {
+		return columns(area, n)
+	}
+	return append(
+		[]xrect.Rect{sliceX(area, 0, 2)},
+		rows(sliceX(area, 1, 2), n-1)...)
+}
+
+// evenGrid makes a grid about as wide as it is high; windows in the
+// last, incomplete row get wider.
+func evenGrid(area xrect.Rect, n int) []xrect.Rect {
+	if n < 1 {
+		return nil
+	}
+
+	cols := int(math.Ceil(math.Sqrt(float64(n))))
+	nrows := (n + cols - 1) / cols
+
+	rv := make([]xrect.Rect, 0, n)
+	for r := 0; r < nrows; r++ {
+		inRow := cols
+		if r == nrows-1 {
+			inRow = n - cols*(nrows-1)
+		}
+		rv = append(rv, columns(sliceY(area, r, nrows), inRow)...)
+	}
+	return rv
+}
+
+// spiral gives each window half of the space left by the previous
+// ones, going clockwise: left, top, right, bottom, left...
+func spiral(area xrect.Rect, n int) []xrect.Rect {
+	rv := make([]xrect.Rect, 0, n)
+	rest := area
+	for i := 0; i < n; i++ {
+		if i == n-1 {
+			rv = append(rv, rest)
+			break
+		}
+
+		var part xrect.Rect
+		switch i % 4 {
+		case 0:
+			part, rest = sliceX(rest, 0, 2), sliceX(rest, 1, 2)
+		case 1:
+			part, rest = sliceY(rest, 0, 2), sliceY(rest, 1, 2)
+		case 2:
+			part, rest = sliceX(rest, 1, 2), sliceX(rest, 0, 2)
+		case 3:
+			part, rest = sliceY(rest, 1, 2), sliceY(rest, 0, 2)
+		}
+		rv = append(rv, part)
+	}
+	return rv
+}
@@ -0,0 +1,80 @@
+package tiler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/BurntSushi/xgbutil/xrect"
+)
+
+func rectString(rs []xrect.Rect) string {
+	s := ""
+	for i, r := range rs {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("%d,%d,%d,%d", r.X(), r.Y(), r.Width(), r.Height())
+	}
+	return s
+}
+
+func TestLayouts(t *testing.T) {
+	area := xrect.New(0, 0, 1200, 900)
+
+	for _, tc := range []struct {
+		arrange func(xrect.Rect, int) []xrect.Rect
+		name    string
+		n       int
+		want    string
+	}{
+		{columns, "columns", 1, "0,0,1200,900"},
+		{columns, "columns", 3, "0,0,400,900 400,0,400,900 800,0,400,900"},
+		{rows, "rows", 2, "0,0,1200,450 0,450,1200,450"},
+		{masterStack, "master-stack", 1, "0,0,1200,900"},
+		{masterStack, "master-stack", 3, "0,0,600,900 600,0,600,450 600,450,600,450"},
+		{evenGrid, "grid", 0, ""},
+		{evenGrid, "grid", 4, "0,0,600,450 600,0,600,450 0,450,600,450 600,450,600,450"},
+		{evenGrid, "grid", 3, "0,0,600,450 600,0,600,450 0,450,1200,450"},
+		{evenGrid, "grid", 5, "0,0,400,450 400,0,400,450 800,0,400,450 0,450,600,450 600,450,600,450"},
+		{spiral, "spiral", 1, "0,0,1200,900"},
+		{spiral, "spiral", 4, "0,0,600,900 600,0,600,450 900,450,300,450 600,450,300,450"},
+		{spiral, "spiral", 5, "0,0,600,900 600,0,600,450 900,450,300,450 600,675,300,225 600,450,300,225"},
+	} {
+		if got := rectString(tc.arrange(area, tc.n)); got != tc.want {
+			t.Errorf("%s(%d) = %s; want %s", tc.name, tc.n, got, tc.want)
+		}
+	}
+}
+
+// Layouts cover the area exactly, with no overlaps, also when it
+// doesn't divide evenly.
+func TestLayoutsCover(t *testing.T) {
+	area := xrect.New(10, 20, 1277, 719)
+	for _, l := range layouts {
+		for n := 1; n <= 9; n++ {
+			rects := l.Arrange(area, n)
+			if len(rects) != n {
+				t.Errorf("%s(%d): %d rectangles", l.Name, n, len(rects))
+				continue
+			}
+
+			total := 0
+			for i, r := range rects {
+				total += r.Width() * r.Height()
+				if r.X() < area.X() || r.Y() < area.Y() ||
+					r.X()+r.Width() > area.X()+area.Width() ||
+					r.Y()+r.Height() > area.Y()+area.Height() {
+					t.Errorf("%s(%d): %d is outside the area", l.Name, n, i)
+				}
+				for j := 0; j < i; j++ {
+					if o := rectBox(r).intersect(rectBox(rects[j])); o.x0 < o.x1 && o.y0 < o.y1 {
+						t.Errorf("%s(%d): %d overlaps %d", l.Name, n, i, j)
+					}
+				}
+			}
+			if total != area.Width()*area.Height() {
+				t.Errorf("%s(%d): covers %d pixels of %d", l.Name, n, total, area.Width()*area.Height())
+			}
+		}
+	}
+}
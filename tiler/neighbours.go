@@ -0,0 +1,126 @@
+package tiler
+
+import (
+	"log"
+
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/mpasternacki/xdwim/daemon"
+	"github.com/mpasternacki/xdwim/desktop"
+)
+
+// neighbour is another window on the same desktop & head, projected
+// onto the grid.
+type neighbour struct {
+	desktop.Window
+	geom           xrect.Rect
+	x0, y0, x1, y1 int
+	label          rune
+}
+
+// topmost first, if WM tells us the stacking order
+var neighbours []neighbour
+
+func loadNeighbours() error {
+	desks, err := daemon.Desktops(sess)
+	if err != nil {
+		return err
+	}
+
+	label := 'a'
+	for _, desk := range desks {
+		if !desk.IsCurrent {
+			continue
+		}
+
+		for _, win := range desk.Windows {
+			if win.XWin == axw || !wm.IsNormal(win.XWin) {
+				continue
+			}
+
+			geom, err := wm.Geometry(win.XWin)
+			if err != nil {
+				log.Printf("WARN: Geometry(%v): %v", win.XWin, err)
+				continue
+			}
+
+			// clip to the head, skip if nothing's left
+			x0, y0 := geom.X()-head.X(), geom.Y()-head.Y()
+			x1, y1 := x0+geom.Width(), y0+geom.Height()
+			if x0 < 0 {
+				x0 = 0
+			}
+			if y0 < 0 {
+				y0 = 0
+			}
+			if x1 > head.Width() {
+				x1 = head.Width()
+			}
+			if y1 > head.Height() {
+				y1 = head.Height()
+			}
+			if x0 >= x1 || y0 >= y1 {
+				continue
+			}
+
+			n := neighbour{Window: win, geom: geom, label: label}
+			n.x0, n.x1 = gridCells(head.Width(), x0, x1)
+			n.y0, n.y1 = gridCells(head.Height(), y0, y1)
+			neighbours = append(neighbours, n)
+
+			if label < 'z' {
+				label++
+			} else {
+				label = '?'
+			}
+		}
+	}
+
+	return nil
+}
+
+// neighbourAt returns topmost neighbour covering the cell, or nil.
+func neighbourAt(x, y int) *neighbour {
+	for i := range neighbours {
+		n := &neighbours[i]
+		if n.x0 <= x && x <= n.x1 && n.y0 <= y && y <= n.y1 {
+			return n
+		}
+	}
+	return nil
+}
+
+// largestFree returns the largest grid rectangle that no neighbour
+// covers, and whether there is any.
+func largestFree() (x0, y0, x1, y1 int, ok bool) {
+	var free [gridSize][gridSize]bool
+	for x := 0; x < gridSize; x++ {
+		for y := 0; y < gridSize; y++ {
+			free[x][y] = neighbourAt(x, y) == nil
+		}
+	}
+
+	best := 0
+	for l := 0; l < gridSize; l++ {
+		for t := 0; t < gridSize; t++ {
+			// grow to the right row by row, narrowing to the free run
+			maxR := gridSize - 1
+			for b := t; b < gridSize; b++ {
+				r := l
+				for r <= maxR && free[r][b] {
+					r++
+				}
+				maxR = r - 1
+				if maxR < l {
+					break
+				}
+				if area := (maxR - l + 1) * (b - t + 1); area > best {
+					best = area
+					x0, y0, x1, y1 = l, t, maxR, b
+				}
+			}
+		}
+	}
+
+	return x0, y0, x1, y1, best > 0
+}
@@ -0,0 +1,61 @@
+package tiler
+
+import "testing"
+
+func TestLargestFree(t *testing.T) {
+	defer func() { neighbours = nil }()
+
+	for _, tc := range []struct {
+		name  string
+		cells [][4]int // x0, y0, x1, y1 of each neighbour
+		want  [4]int
+		ok    bool
+	}{
+		{"empty", nil, [4]int{0, 0, 11, 11}, true},
+		{"left half taken", [][4]int{{0, 0, 5, 11}}, [4]int{6, 0, 11, 11}, true},
+		{"top two thirds taken", [][4]int{{0, 0, 11, 7}}, [4]int{0, 8, 11, 11}, true},
+		{"column in the middle", [][4]int{{4, 0, 4, 11}}, [4]int{5, 0, 11, 11}, true},
+		// as large as the one at top right; the leftmost wins
+		{"corners taken", [][4]int{{0, 0, 2, 2}, {9, 9, 11, 11}}, [4]int{0, 3, 8, 11}, true},
+		{"all taken", [][4]int{{0, 0, 11, 11}}, [4]int{}, false},
+	} {
+		neighbours = nil
+		for _, c := range tc.cells {
+			neighbours = append(neighbours, neighbour{x0: c[0], y0: c[1], x1: c[2], y1: c[3]})
+		}
+
+		x0, y0, x1, y1, ok := largestFree()
+		if ok != tc.ok || (ok && [4]int{x0, y0, x1, y1} != tc.want) {
+			t.Errorf("%s: largestFree = %d,%d,%d,%d %v; want %v %v",
+				tc.name, x0, y0, x1, y1, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestNeighbourAt(t *testing.T) {
+	defer func() { neighbours = nil }()
+	neighbours = []neighbour{
+		{x0: 2, y0: 2, x1: 5, y1: 5, label: 'a'},
+		{x0: 0, y0: 0, x1: 11, y1: 11, label: 'b'},
+	}
+
+	for _, tc := range []struct {
+		x, y int
+		want rune
+	}{
+		{2, 2, 'a'},
+		{5, 5, 'a'},
+		{6, 5, 'b'},
+		{0, 11, 'b'},
+	} {
+		n := neighbourAt(tc.x, tc.y)
+		if n == nil || n.label != tc.want {
+			t.Errorf("neighbourAt(%d, %d) = %v; want %c", tc.x, tc.y, n, tc.want)
+		}
+	}
+
+	neighbours = neighbours[:1]
+	if n := neighbourAt(8, 8); n != nil {
+		t.Errorf("neighbourAt(8, 8) = %c; want none", n.label)
+	}
+}
@@ -0,0 +1,209 @@
+package tiler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/mpasternacki/termbox-go"
+
+	"github.com/mpasternacki/xdwim/tui"
+	"github.com/mpasternacki/xdwim/urxvtermbox"
+)
+
+var stepFlag = flags.String("step", "", "`STEP` for nudge: pixels (10), percent of the screen (5%),\n"+
+	"or inc for window's own resize increment (default from config file, or 10)")
+
+// step is how far nudge moves an edge, in pixels.
+type step struct {
+	x, y int
+	desc string
+}
+
+func parseStep(spec string) (step, error) {
+	switch {
+	case spec == "":
+		return step{10, 10, "10px"}, nil
+	case spec == "inc":
+		st := step{hints.incW, hints.incH, "inc"}
+		// window doesn't resize in steps
+		if st.x < 2 {
+			st.x = 10
+		}
+		if st.y < 2 {
+			st.y = 10
+		}
+		return st, nil
+	case strings.HasSuffix(spec, "%"):
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(spec, "%"), 64)
+		if err != nil || pct <= 0 {
+			return step{}, fmt.Errorf("bad step %q", spec)
+		}
+		return step{
+			int(float64(head.Width())*pct/100 + 0.5),
+			int(float64(head.Height())*pct/100 + 0.5),
+			spec,
+		}, nil
+	default:
+		px, err := strconv.Atoi(strings.TrimSuffix(spec, "px"))
+		if err != nil || px <= 0 {
+			return step{}, fmt.Errorf("bad step %q", spec)
+		}
+		return step{px, px, fmt.Sprintf("%dpx", px)}, nil
+	}
+}
+
+// nudge actions: direction of the move, or of the edge to grow or
+// shrink; moves use the same actions as the grid cursor
+var (
+	nudgeMove = map[string][2]int{
+		"left":  {-1, 0},
+		"right": {1, 0},
+		"up":    {0, -1},
+		"down":  {0, 1},
+	}
+	nudgeGrow = map[string][2]int{
+		"grow-left":  {-1, 0},
+		"grow-right": {1, 0},
+		"grow-up":    {0, -1},
+		"grow-down":  {0, 1},
+	}
+	nudgeShrink = map[string][2]int{
+		"shrink-left":  {-1, 0},
+		"shrink-right": {1, 0},
+		"shrink-up":    {0, -1},
+		"shrink-down":  {0, 1},
+	}
+)
+
+// nudgeBox applies a move, grow or shrink by n steps.
+func nudgeBox(b box, st step, n int, dir [2]int, move, grow bool) box {
+	dx, dy := dir[0]*st.x*n, dir[1]*st.y*n
+	switch {
+	case move:
+		b.x0 += dx
+		b.x1 += dx
+		b.y0 += dy
+		b.y1 += dy
+	case grow:
+		// edge in dir moves outwards
+		if dx < 0 || dy < 0 {
+			b.x0 += dx
+			b.y0 += dy
+		} else {
+			b.x1 += dx
+			b.y1 += dy
+		}
+	default:
+		// edge in dir moves inwards
+		if dx < 0 || dy < 0 {
+			b.x0 -= dx
+			b.y0 -= dy
+		} else {
+			b.x1 -= dx
+			b.y1 -= dy
+		}
+	}
+
+	if b.x1-b.x0 < st.x {
+		b.x1 = b.x0 + st.x
+	}
+	if b.y1-b.y0 < st.y {
+		b.y1 = b.y0 + st.y
+	}
+	return b
+}
+
+func drawNudge(b box, st step, count int) {
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	drawString(0, 0, fmt.Sprintf("x %5d  y %5d", b.x0, b.y0), termbox.ColorDefault)
+	drawString(0, 1, fmt.Sprintf("w %5d  h %5d", b.x1-b.x0, b.y1-b.y0), termbox.ColorDefault)
+	col := drawString(0, 2, "step ", termbox.ColorDefault)
+	col = drawString(col, 2, st.desc, colors["step"])
+	if count > 1 {
+		drawString(col, 2, fmt.Sprintf(" ×%d", count), colors["count"])
+	}
+	drawString(0, 3, "←↑↓→ move, shift grow, ctrl shrink", colors["help"])
+	termbox.Flush()
+}
+
+// nudge moves and resizes the window by steps, live; Esc puts it back.
+func nudge(spec string) error {
+	st, err := parseStep(spec)
+	if err != nil {
+		return err
+	}
+
+	if len(origState) > 0 {
+		if err := wm.SetState(axw, ewmh.StateRemove, origState...); err != nil {
+			return err
+		}
+	}
+
+	b, cancelled, err := nudgeMain(st)
+	if err != nil || cancelled {
+		restoreGeom()
+		if len(origState) > 0 {
+			if err := wm.SetState(axw, ewmh.StateAdd, origState...); err != nil {
+				log.Println("ERROR restoring state:", err)
+			}
+		}
+		return err
+	}
+
+	return place(b.x0, b.y0, b.x1-b.x0, b.y1-b.y0)
+}
+
+func nudgeMain(st step) (b box, cancelled bool, erv error) {
+	if fini, err := urxvtermbox.TermboxUrxvt(36, 4, urxvtArgs...); err != nil {
+		return b, false, err
+	} else {
+		defer fini()
+	}
+
+	tui.SetInputMode(0)
+
+	b = rectBox(origGeom)
+	count := 1
+	drawNudge(b, st, count)
+	for {
+		switch ev := tui.PollEvent(); ev.Type {
+		case termbox.EventKey:
+			action := keymap.Action(ev)
+			if dir, ok := nudgeMove[action]; ok {
+				b = nudgeBox(b, st, count, dir, true, false)
+			} else if dir, ok := nudgeGrow[action]; ok {
+				b = nudgeBox(b, st, count, dir, false, true)
+			} else if dir, ok := nudgeShrink[action]; ok {
+				b = nudgeBox(b, st, count, dir, false, false)
+			} else if n, err := strconv.Atoi(strings.TrimPrefix(action, "prefix-")); err == nil && n <= 9 {
+				count = n
+				drawNudge(b, st, count)
+				continue
+			} else if action == "choose" || action == "mark" {
+				return b, false, nil
+			} else if action == "cancel" || action == "unmark" {
+				return b, true, nil
+			} else {
+				continue
+			}
+			count = 1
+
+			// live
+			if err := wm.MoveResize(axw, b.x0, b.y0, b.x1-b.x0, b.y1-b.y0); err != nil {
+				log.Println("ERROR moving window:", err)
+			}
+			xu.Sync()
+		case termbox.EventInterrupt:
+			return b, true, nil
+		case termbox.EventError:
+			return b, true, ev.Err
+		}
+		drawNudge(b, st, count)
+	}
+
+	return b, true, errors.New("CAN'T HAPPEN")
+}
@@ -0,0 +1,172 @@
+package tiler
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xrect"
+)
+
+// box is a rectangle given by its edges; x1 and y1 are exclusive.
+type box struct{ x0, y0, x1, y1 int }
+
+func rectBox(r xrect.Rect) box {
+	return box{r.X(), r.Y(), r.X() + r.Width(), r.Y() + r.Height()}
+}
+
+func (b box) rect() xrect.Rect {
+	return xrect.New(b.x0, b.y0, b.x1-b.x0, b.y1-b.y0)
+}
+
+// orient transposes and mirrors the box so that direction dir becomes
+// "left". This way only moves to the left need to be written down.
+func (b box) orient(dir string) box {
+	switch dir {
+	case "right":
+		return box{-b.x1, b.y0, -b.x0, b.y1}
+	case "up":
+		return box{b.y0, b.x0, b.y1, b.x1}
+	case "down":
+		return box{-b.y1, b.x0, -b.y0, b.x1}
+	}
+	return b
+}
+
+// unorient undoes orient.
+func (b box) unorient(dir string) box {
+	if dir == "down" {
+		return box{b.y0, -b.x1, b.y1, -b.x0}
+	}
+	return b.orient(dir)
+}
+
+// stop returns how far left w's left edge can go before it touches an
+// obstacle or bound.
+func (w box) stop(obstacles []box, bound box) int {
+	// windows stay as far apart as on the grid, and touch the bound
+	limit := bound.x0
+	for _, o := range obstacles {
+		if o.y0 < w.y1 && o.y1 > w.y0 && o.x1 <= w.x0 {
+			if edge := o.x1 + cellGap; edge > limit {
+				limit = edge
+			}
+		}
+	}
+	if limit > w.x0 {
+		// already closer than the gap; stay put
+		return w.x0
+	}
+	return limit
+}
+
+// retreat returns where w's left edge must go to stop overlapping
+// obstacles and to get back within bound.
+func (w box) retreat(obstacles []box, bound box) int {
+	limit := max(w.x0, bound.x0)
+	for _, o := range obstacles {
+		if o.y0 < w.y1 && o.y1 > w.y0 && o.x1 > w.x0 && o.x1 < w.x1 {
+			if edge := o.x1 + cellGap; edge > limit {
+				limit = edge
+			}
+		}
+	}
+	if limit >= w.x1 {
+		return w.x0
+	}
+	return limit
+}
+
+// workArea returns the part of r that is in the current desktop's
+// work area, away from panels and docks.
+func workArea(r xrect.Rect) box {
+	b := rectBox(r)
+	if desk, err := ewmh.CurrentDesktopGet(xu); err == nil {
+		if was, err := ewmh.WorkareaGet(xu); err == nil && int(desk) < len(was) {
+			wa := was[desk]
+			b = b.intersect(box{wa.X, wa.Y, wa.X + int(wa.Width), wa.Y + int(wa.Height)})
+		} else if err != nil {
+			log.Printf("WARN: WorkareaGet: %v", err)
+		}
+	}
+	return b
+}
+
+// pixelOp computes the active window's new geometry after growing
+// ("grow"), shrinking ("shrink") or moving ("move") it towards dir.
+func pixelOp(op, dir string) (box, error) {
+	if dir != "left" && dir != "right" && dir != "up" && dir != "down" {
+		return box{}, fmt.Errorf("unknown direction %q", dir)
+	}
+
+	bound := workArea(head)
+
+	obstacles := make([]box, len(neighbours))
+	for i, n := range neighbours {
+		obstacles[i] = rectBox(n.geom).orient(dir)
+	}
+
+	w := rectBox(origGeom).orient(dir)
+	bound = bound.orient(dir)
+
+	switch op {
+	case "grow":
+		w.x0 = w.stop(obstacles, bound)
+	case "shrink":
+		w.x0 = w.retreat(obstacles, bound)
+	case "move":
+		dx := w.stop(obstacles, bound) - w.x0
+		w.x0 += dx
+		w.x1 += dx
+	default:
+		return box{}, fmt.Errorf("unknown operation %q", op)
+	}
+
+	return w.unorient(dir), nil
+}
+
+func (b box) intersect(o box) box {
+	if o.x0 > b.x0 {
+		b.x0 = o.x0
+	}
+	if o.y0 > b.y0 {
+		b.y0 = o.y0
+	}
+	if o.x1 < b.x1 {
+		b.x1 = o.x1
+	}
+	if o.y1 < b.y1 {
+		b.y1 = o.y1
+	}
+	return b
+}
+
+// opposite edge of the window stays put when it's resized
+var anchors = map[string]alignment{
+	"left":  {1, 0},
+	"right": {-1, 0},
+	"up":    {0, 1},
+	"down":  {0, -1},
+}
+
+// applyPixelOp grows, shrinks or moves the active window.
+func applyPixelOp(op, dir string) error {
+	b, err := pixelOp(op, dir)
+	if err != nil {
+		return err
+	}
+
+	if len(origState) > 0 {
+		err := wm.SetState(axw, ewmh.StateRemove, origState...)
+		if err != nil {
+			return err
+		}
+	}
+
+	x, y, w, h := b.x0, b.y0, b.x1-b.x0, b.y1-b.y0
+	if op != "move" {
+		x, y, w, h = fitRectFor(hints, extents, anchors[dir], x, y, w, h)
+	}
+
+	return place(x, y, w, h)
+}
@@ -0,0 +1,88 @@
+package tiler
+
+import "testing"
+
+func TestOrientRoundTrip(t *testing.T) {
+	b := box{10, 20, 110, 220}
+	for _, dir := range []string{"left", "right", "up", "down"} {
+		o := b.orient(dir)
+		if o.x0 >= o.x1 || o.y0 >= o.y1 {
+			t.Errorf("%s: oriented box %v is inside out", dir, o)
+		}
+		if u := o.unorient(dir); u != b {
+			t.Errorf("%s: %v → %v → %v", dir, b, o, u)
+		}
+	}
+}
+
+func TestStop(t *testing.T) {
+	bound := box{0, 0, 1920, 1080}
+	w := box{1000, 100, 1500, 500}
+
+	for _, tc := range []struct {
+		name      string
+		obstacles []box
+		want      int
+	}{
+		{"nothing in the way", nil, 0},
+		{"window to the left", []box{{200, 0, 600, 300}}, 600 + cellGap},
+		{"nearest of two", []box{{0, 0, 300, 1080}, {200, 400, 700, 900}}, 700 + cellGap},
+		{"above, not in the way", []box{{200, 0, 900, 100}}, 0},
+		{"overlapping, not to the left", []box{{1200, 0, 1300, 1080}}, 0},
+		{"already too close", []box{{0, 0, 999, 1080}}, 1000},
+	} {
+		if got := w.stop(tc.obstacles, bound); got != tc.want {
+			t.Errorf("%s: stop = %d; want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRetreat(t *testing.T) {
+	bound := box{0, 0, 1920, 1080}
+
+	for _, tc := range []struct {
+		name      string
+		w         box
+		obstacles []box
+		want      int
+	}{
+		{"nothing to avoid", box{100, 100, 900, 500}, nil, 100},
+		{"past the bound", box{-50, 100, 900, 500}, nil, 0},
+		{"overlapped on the left", box{100, 100, 900, 500}, []box{{0, 0, 400, 1080}}, 400 + cellGap},
+		{"overlapped by two", box{100, 100, 900, 500}, []box{{0, 0, 400, 1080}, {300, 300, 600, 600}}, 600 + cellGap},
+		{"covered entirely", box{100, 100, 900, 500}, []box{{0, 0, 1000, 1080}}, 100},
+		{"obstacle to the right", box{100, 100, 900, 500}, []box{{950, 0, 1920, 1080}}, 100},
+	} {
+		if got := tc.w.retreat(tc.obstacles, bound); got != tc.want {
+			t.Errorf("%s: retreat = %d; want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+// Moving right is moving left in a mirrored world.
+func TestStopOriented(t *testing.T) {
+	bound := box{0, 0, 1920, 1080}.orient("right")
+	w := box{100, 100, 500, 500}.orient("right")
+	obstacles := []box{box{1200, 0, 1600, 1080}.orient("right")}
+
+	dx := w.stop(obstacles, bound) - w.x0
+	w.x0 += dx
+	w.x1 += dx
+	if got, want := w.unorient("right"), (box{800 - cellGap, 100, 1200 - cellGap, 500}); got != want {
+		t.Errorf("moved right to %v; want %v", got, want)
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	for _, tc := range []struct {
+		a, b, want box
+	}{
+		{box{0, 0, 1920, 1080}, box{0, 30, 1920, 1080}, box{0, 30, 1920, 1080}},
+		{box{1920, 0, 3840, 1080}, box{0, 30, 3840, 1050}, box{1920, 30, 3840, 1050}},
+		{box{0, 0, 100, 100}, box{50, 50, 200, 200}, box{50, 50, 100, 100}},
+	} {
+		if got := tc.a.intersect(tc.b); got != tc.want {
+			t.Errorf("%v ∩ %v = %v; want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
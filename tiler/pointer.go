@@ -0,0 +1,240 @@
+package tiler
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgb/xtest"
+	"github.com/BurntSushi/xgbutil/xrect"
+	"github.com/mpasternacki/termbox-go"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/config"
+)
+
+// PointerCommand is the "pointer" command of xdwim.
+var PointerCommand = xdwim.Command{
+	Name:     "pointer",
+	Synopsis: "move the mouse pointer and click with the tiler grid",
+	Run:      RunPointer,
+	Usage:    pointerUsage,
+}
+
+var pointerFlags = flag.NewFlagSet("pointer", flag.ContinueOnError)
+
+var pointerPreviewFlag = pointerFlags.Bool("preview", true, "outline the cell under cursor on the screen")
+
+func pointerUsage(prog string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS]\n\nOptions:\n", prog)
+	pointerFlags.PrintDefaults()
+}
+
+// In pointer mode the grid covers an area of the screen, the head
+// under the pointer at first. Zooming makes the cell under the cursor
+// the new area, so that a few zooms get to a single pixel.
+var (
+	pointerMode = false
+	// zoomed out areas, to go back to
+	areas []xrect.Rect
+	// pointer position before we started
+	origPointerX, origPointerY int
+	// button to click when done, 0 for none
+	clickButton byte
+	// move the pointer when done
+	doWarp = false
+)
+
+// RunPointer shows the grid, and moves the pointer and clicks where
+// the user says.
+func RunPointer(s *xdwim.Session, args []string) error {
+	begin(s)
+
+	pointerFlags.Usage = func() {}
+	if err := pointerFlags.Parse(args); err != nil || pointerFlags.NArg() > 0 {
+		return xdwim.ErrUsage
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := configure(cfg.Tiler); err != nil {
+		return err
+	}
+	// the pointer will leave the terminal
+	urxvtArgs = nil
+
+	if err := xtest.Init(xu.Conn()); err != nil {
+		return err
+	}
+
+	ptr, err := xproto.QueryPointer(xu.Conn(), xu.RootWin()).Reply()
+	if err != nil {
+		return err
+	}
+	origPointerX, origPointerY = int(ptr.RootX), int(ptr.RootY)
+
+	head, err = sess.HeadOf(xrect.New(origPointerX, origPointerY, 1, 1))
+	if err != nil {
+		return err
+	}
+	pointerMode = true
+	pointerCell()
+
+	if *pointerPreviewFlag {
+		if pv, err = newPreview(); err != nil {
+			return err
+		}
+	}
+	err = uiMain()
+	stopPreview()
+	if err != nil {
+		return err
+	}
+
+	if clickButton == 0 && !doWarp {
+		return warp(origPointerX, origPointerY)
+	}
+
+	x, y := pointerSpot()
+	if err := warp(x, y); err != nil {
+		return err
+	}
+	if clickButton == 0 {
+		return nil
+	}
+
+	// let the terminal and the outline go away, so that the click gets
+	// to what's under them
+	waitRepaint()
+	for _, typ := range []byte{xproto.ButtonPress, xproto.ButtonRelease} {
+		err := xtest.FakeInputChecked(xu.Conn(), typ, clickButton, 0, xu.RootWin(),
+			int16(x), int16(y), 0).Check()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pointerCell puts cursor on the cell with the pointer, or in the
+// middle of the area if it's outside.
+func pointerCell() {
+	x, y := origPointerX-head.X(), origPointerY-head.Y()
+	if x < 0 || x >= head.Width() || y < 0 || y >= head.Height() {
+		posX, posY = gridSize/2, gridSize/2
+	} else {
+		posX, _ = gridCells(head.Width(), x, x+1)
+		posY, _ = gridCells(head.Height(), y, y+1)
+	}
+	origX0, origX1, origY0, origY1 = posX, posX, posY, posY
+}
+
+// cursorCell returns the cell under cursor in screen coordinates.
+func cursorCell() (x, y, w, h int) {
+	x, w = gridSpan(head.Width(), posX, posX)
+	y, h = gridSpan(head.Height(), posY, posY)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return head.X() + x, head.Y() + y, w, h
+}
+
+// pointerSpot returns the middle of the cell under cursor.
+func pointerSpot() (int, int) {
+	x, y, w, h := cursorCell()
+	return x + w/2, y + h/2
+}
+
+func warp(x, y int) error {
+	return xproto.WarpPointerChecked(xu.Conn(), 0, xu.RootWin(), 0, 0, 0, 0,
+		int16(x), int16(y)).Check()
+}
+
+// zoom makes the cell under cursor the whole grid, and moves the
+// pointer there.
+func zoom() {
+	if head.Width() <= 1 && head.Height() <= 1 {
+		return
+	}
+	areas = append(areas, head)
+	head = xrect.New(cursorCell())
+	if err := warp(pointerSpot()); err != nil {
+		log.Printf("WARN: can't move pointer: %v", err)
+	}
+	posX, posY = gridSize/2, gridSize/2
+	origX0, origX1, origY0, origY1 = -1, -1, -1, -1
+}
+
+// unzoom goes back to the previous area, with the cursor on the cell
+// we zoomed into.
+func unzoom() {
+	if len(areas) == 0 {
+		return
+	}
+	zoomed := head
+	head = areas[len(areas)-1]
+	areas = areas[:len(areas)-1]
+	posX, _ = gridCells(head.Width(), zoomed.X()-head.X(), zoomed.X()-head.X()+1)
+	posY, _ = gridCells(head.Height(), zoomed.Y()-head.Y(), zoomed.Y()-head.Y()+1)
+}
+
+// pointerKey handles key event in pointer mode. Actions not handled
+// here move the cursor as usual.
+func pointerKey(ev termbox.Event) (handled, done bool) {
+	switch keymap.Action(ev) {
+	case "cancel":
+		return true, true
+	case "choose":
+		clickButton = 1
+		return true, true
+	case "click-middle":
+		clickButton = 2
+		return true, true
+	case "click-right":
+		clickButton = 3
+		return true, true
+	case "warp":
+		doWarp = true
+		return true, true
+	case "mark":
+		zoom()
+		return true, false
+	case "unmark":
+		unzoom()
+		return true, false
+	case "up", "down", "left", "right", "top", "bottom", "leftmost", "rightmost", "column", "row",
+		"prefix-1", "prefix-2", "prefix-3", "prefix-4", "prefix-5", "prefix-6",
+		"prefix-7", "prefix-8", "prefix-9", "prefix-10", "prefix-11", "prefix-12":
+		return false, false
+	}
+	// anything else makes no sense here
+	return true, false
+}
+
+// drawPointerStatus shows the area size and the spot the pointer will
+// go to.
+func drawPointerStatus() {
+	for j := 14; j <= 15; j++ {
+		for i := 0; i < 28; i++ {
+			termbox.SetCell(i, j, ' ', termbox.ColorDefault, termbox.ColorDefault)
+		}
+	}
+	x, y := pointerSpot()
+	drawString(2, 14, fmt.Sprintf("%d×%d", head.Width(), head.Height()), colors["size"])
+	drawString(0, 15, fmt.Sprintf("→ %d,%d", x, y), colors["preset"])
+}
+
+// updatePointerPreview outlines the cell under cursor.
+func updatePointerPreview() {
+	if pv != nil {
+		x, y, w, h := cursorCell()
+		pv.show(x-previewWidth, y-previewWidth, w+2*previewWidth, h+2*previewWidth)
+	}
+}
@@ -0,0 +1,114 @@
+package tiler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// preset is a named rectangle on the grid, in cells.
+type preset struct {
+	Name       string
+	X, Y, W, H int
+}
+
+// Built-in presets. Config file can redefine these or add more.
+var defaultPresets = []preset{
+	{"full", 0, 0, gridSize, gridSize},
+	{"left-half", 0, 0, gridSize / 2, gridSize},
+	{"right-half", gridSize / 2, 0, gridSize / 2, gridSize},
+	{"top-half", 0, 0, gridSize, gridSize / 2},
+	{"bottom-half", 0, gridSize / 2, gridSize, gridSize / 2},
+	{"top-left", 0, 0, gridSize / 2, gridSize / 2},
+	{"top-right", gridSize / 2, 0, gridSize / 2, gridSize / 2},
+	{"bottom-left", 0, gridSize / 2, gridSize / 2, gridSize / 2},
+	{"bottom-right", gridSize / 2, gridSize / 2, gridSize / 2, gridSize / 2},
+	{"left-third", 0, 0, gridSize / 3, gridSize},
+	{"center-third", gridSize / 3, 0, gridSize / 3, gridSize},
+	{"right-third", 2 * gridSize / 3, 0, gridSize / 3, gridSize},
+	{"left-two-thirds", 0, 0, 2 * gridSize / 3, gridSize},
+	{"right-two-thirds", gridSize / 3, 0, 2 * gridSize / 3, gridSize},
+	{"center", gridSize / 6, 0, 2 * gridSize / 3, gridSize},
+}
+
+// cells returns the preset's first and last column and row.
+func (p preset) cells() (x0, y0, x1, y1 int) {
+	return p.X, p.Y, p.X + p.W - 1, p.Y + p.H - 1
+}
+
+func (p preset) String() string {
+	return fmt.Sprintf("%s %d,%d,%d,%d", p.Name, p.X, p.Y, p.W, p.H)
+}
+
+// parseRect parses "x,y,w,h" grid rectangle.
+func parseRect(spec string) (preset, error) {
+	p := preset{Name: spec}
+
+	parts := strings.Split(spec, ",")
+	if len(parts) != 4 {
+		return p, fmt.Errorf("%q: want x,y,w,h", spec)
+	}
+
+	nums := make([]int, 4)
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return p, fmt.Errorf("%q: %v", spec, err)
+		}
+		nums[i] = n
+	}
+	p.X, p.Y, p.W, p.H = nums[0], nums[1], nums[2], nums[3]
+
+	if p.X < 0 || p.Y < 0 || p.W < 1 || p.H < 1 ||
+		p.X+p.W > gridSize || p.Y+p.H > gridSize {
+		return p, fmt.Errorf("%q: doesn't fit %dx%d grid", spec, gridSize, gridSize)
+	}
+
+	return p, nil
+}
+
+// loadPresets merges presets from config file into the defaults.
+func loadPresets(conf map[string]string) ([]preset, error) {
+	presets := append([]preset{}, defaultPresets...)
+
+	names := make([]string, 0, len(conf))
+	for name := range conf {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+outer:
+	for _, name := range names {
+		p, err := parseRect(conf[name])
+		if err != nil {
+			return nil, fmt.Errorf("preset %s: %v", name, err)
+		}
+		p.Name = name
+
+		for i := range presets {
+			if presets[i].Name == name {
+				presets[i] = p
+				continue outer
+			}
+		}
+		presets = append(presets, p)
+	}
+
+	return presets, nil
+}
+
+// findPreset returns preset by name, or parses spec as "x,y,w,h".
+func findPreset(presets []preset, spec string) (preset, error) {
+	for _, p := range presets {
+		if p.Name == spec {
+			return p, nil
+		}
+	}
+
+	if strings.Contains(spec, ",") {
+		return parseRect(spec)
+	}
+
+	return preset{}, fmt.Errorf("unknown preset %q", spec)
+}
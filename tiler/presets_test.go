@@ -0,0 +1,77 @@
+package tiler
+
+import "testing"
+
+func TestParseRect(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want preset
+		ok   bool
+	}{
+		{"0,0,12,12", preset{"0,0,12,12", 0, 0, 12, 12}, true},
+		{"3, 2, 6, 8", preset{"3, 2, 6, 8", 3, 2, 6, 8}, true},
+		{"11,11,1,1", preset{"11,11,1,1", 11, 11, 1, 1}, true},
+		{"0,0,12", preset{}, false},
+		{"0,0,12,12,1", preset{}, false},
+		{"a,0,1,1", preset{}, false},
+		{"-1,0,1,1", preset{}, false},
+		{"0,0,0,1", preset{}, false},
+		{"6,0,7,12", preset{}, false},
+		{"0,6,12,7", preset{}, false},
+	} {
+		p, err := parseRect(tc.spec)
+		if (err == nil) != tc.ok {
+			t.Errorf("parseRect(%q): error %v", tc.spec, err)
+			continue
+		}
+		if tc.ok && p != tc.want {
+			t.Errorf("parseRect(%q) = %v; want %v", tc.spec, p, tc.want)
+		}
+	}
+}
+
+func TestLoadPresets(t *testing.T) {
+	presets, err := loadPresets(map[string]string{
+		"full":  "1,1,10,10",
+		"dock":  "0,9,12,3",
+		"aside": "9,0,3,12",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(presets) != len(defaultPresets)+2 {
+		t.Errorf("got %d presets; want %d", len(presets), len(defaultPresets)+2)
+	}
+	for _, tc := range []struct {
+		spec string
+		want preset
+	}{
+		{"full", preset{"full", 1, 1, 10, 10}},
+		{"left-half", preset{"left-half", 0, 0, 6, 12}},
+		{"dock", preset{"dock", 0, 9, 12, 3}},
+		{"aside", preset{"aside", 9, 0, 3, 12}},
+		{"2,2,4,4", preset{"2,2,4,4", 2, 2, 4, 4}},
+	} {
+		p, err := findPreset(presets, tc.spec)
+		if err != nil {
+			t.Errorf("findPreset(%q): %v", tc.spec, err)
+		} else if p != tc.want {
+			t.Errorf("findPreset(%q) = %v; want %v", tc.spec, p, tc.want)
+		}
+	}
+
+	if _, err := findPreset(presets, "nonesuch"); err == nil {
+		t.Error("findPreset(nonesuch): no error")
+	}
+	if _, err := loadPresets(map[string]string{"bad": "0,0,13,1"}); err == nil {
+		t.Error("loadPresets with a rectangle off the grid: no error")
+	}
+}
+
+func TestPresetCells(t *testing.T) {
+	x0, y0, x1, y1 := preset{"", 6, 0, 6, 12}.cells()
+	if x0 != 6 || y0 != 0 || x1 != 11 || y1 != 11 {
+		t.Errorf("cells = %d,%d,%d,%d; want 6,0,11,11", x0, y0, x1, y1)
+	}
+}
@@ -0,0 +1,127 @@
+package tiler
+
+import (
+	"log"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/xwindow"
+)
+
+var (
+	previewFlag = flags.Bool("preview", true, "outline the selection on the screen")
+	liveFlag    = flags.Bool("live", false, "move the window itself while selecting")
+)
+
+const (
+	previewWidth = 3        // of the outline
+	previewColor = 0xffd700 // gold
+)
+
+// preview is an outline on the screen made of four override-redirect
+// windows, one for each side.
+type preview struct {
+	sides   [4]*xwindow.Window
+	visible bool
+}
+
+var pv *preview
+
+func newPreview() (*preview, error) {
+	p := &preview{}
+	for i := range p.sides {
+		win, err := xwindow.Generate(xu)
+		if err != nil {
+			p.destroy()
+			return nil, err
+		}
+		err = win.CreateChecked(xu.RootWin(), 0, 0, 1, 1,
+			xproto.CwBackPixel|xproto.CwOverrideRedirect,
+			previewColor, 1)
+		if err != nil {
+			p.destroy()
+			return nil, err
+		}
+		p.sides[i] = win
+	}
+	return p, nil
+}
+
+func (p *preview) show(x, y, w, h int) {
+	t := previewWidth
+	if w < 2*t || h < 2*t {
+		p.hide()
+		return
+	}
+
+	p.sides[0].MoveResize(x, y, w, t)
+	p.sides[1].MoveResize(x, y+h-t, w, t)
+	p.sides[2].MoveResize(x, y+t, t, h-2*t)
+	p.sides[3].MoveResize(x+w-t, y+t, t, h-2*t)
+	for _, side := range p.sides {
+		side.Stack(xproto.StackModeAbove)
+		if !p.visible {
+			side.Map()
+		}
+	}
+	p.visible = true
+	xu.Sync()
+}
+
+func (p *preview) hide() {
+	if !p.visible {
+		return
+	}
+	for _, side := range p.sides {
+		side.Unmap()
+	}
+	p.visible = false
+	xu.Sync()
+}
+
+func (p *preview) destroy() {
+	for _, side := range p.sides {
+		if side != nil {
+			side.Destroy()
+		}
+	}
+	xu.Sync()
+}
+
+// liveMoved is set once live mode moved the window
+var liveMoved = false
+
+// updatePreview follows selection with the outline, or with the
+// window itself in live mode.
+func updatePreview() {
+	if markX < 0 {
+		if pv != nil {
+			pv.hide()
+		}
+		if *liveFlag && liveMoved {
+			restoreGeom()
+		}
+		return
+	}
+
+	x, y, w, h := fitRect(cellRect(posX, posY, markX, markY))
+	if pv != nil {
+		pv.show(x, y, w, h)
+	}
+	if *liveFlag && !regionMode {
+		if err := wm.MoveResize(axw, x, y, w, h); err != nil {
+			log.Println("ERROR moving window:", err)
+		}
+		liveMoved = true
+		xu.Sync()
+	}
+}
+
+// restoreGeom puts window back where it was after live mode moved it.
+func restoreGeom() {
+	x, y, w, h := origGeom.Pieces()
+	if err := wm.MoveResize(axw, x, y, w, h); err != nil {
+		log.Println("ERROR restoring geometry:", err)
+	}
+	liveMoved = false
+	xu.Sync()
+}
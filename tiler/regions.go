@@ -0,0 +1,215 @@
+package tiler
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/mpasternacki/termbox-go"
+
+	"github.com/mpasternacki/xdwim/daemon"
+	"github.com/mpasternacki/xdwim/desktop"
+)
+
+// region is a rectangle on the grid with a window to put there.
+type region struct {
+	x0, y0, x1, y1 int
+	win            *listItem
+}
+
+type listItem struct {
+	desktop.Window
+	desk uint
+}
+
+const listWidth = 40
+
+var (
+	regionMode = false
+	regions    []region
+	listMode   = false // choosing window for the last region
+	listItems  []listItem
+	listSel    = 0
+	listTop    = 0
+	curDesk    uint
+)
+
+// number of colours of the assigned regions, region-1 to region-N in
+// colors, used in order
+const regionColors = 5
+
+func regionColor(i int) termbox.Attribute {
+	return colors["region-"+strconv.Itoa(i%regionColors+1)]
+}
+
+// regionAt returns index of the newest region covering the cell, or -1.
+func regionAt(x, y int) int {
+	for i := len(regions) - 1; i >= 0; i-- {
+		r := regions[i]
+		if r.x0 <= x && x <= r.x1 && r.y0 <= y && y <= r.y1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadList lists windows of all desktops to choose from, current
+// desktop first.
+func loadList() error {
+	desks, err := daemon.Desktops(sess)
+	if err != nil {
+		return err
+	}
+
+	for _, desk := range desks {
+		if desk.IsCurrent {
+			curDesk = desk.Number
+			for _, win := range desk.Windows {
+				listItems = append(listItems, listItem{win, desk.Number})
+			}
+		}
+	}
+	for _, desk := range desks {
+		if !desk.IsCurrent {
+			for _, win := range desk.Windows {
+				listItems = append(listItems, listItem{win, desk.Number})
+			}
+		}
+	}
+
+	if len(listItems) == 0 {
+		return fmt.Errorf("no windows")
+	}
+
+	return nil
+}
+
+// addRegion turns current selection into a new region and lets user
+// choose its window.
+func addRegion() {
+	l, r, t, b := posX, markX, posY, markY
+	if l > r {
+		l, r = r, l
+	}
+	if t > b {
+		t, b = b, t
+	}
+	regions = append(regions, region{x0: l, y0: t, x1: r, y1: b})
+	markX, markY = -1, -1
+	listMode = true
+}
+
+// assigned returns index of region the window is assigned to, or -1.
+func assigned(item *listItem) int {
+	for i, r := range regions {
+		if r.win == item {
+			return i
+		}
+	}
+	return -1
+}
+
+func drawList() {
+	_, rows := termbox.Size()
+
+	if listSel < listTop {
+		listTop = listSel
+	}
+	if listSel >= listTop+rows {
+		listTop = listSel - rows + 1
+	}
+
+	for row := 0; row < rows; row++ {
+		for col := 29; col < 29+listWidth; col++ {
+			termbox.SetCell(col, row, ' ', termbox.ColorDefault, termbox.ColorDefault)
+		}
+
+		i := listTop + row
+		if i >= len(listItems) {
+			continue
+		}
+		item := &listItems[i]
+
+		fg := termbox.ColorDefault
+		if item.desk != curDesk {
+			fg = colors["label"]
+		}
+		if ri := assigned(item); ri >= 0 {
+			fg = regionColor(ri) | termbox.AttrBold
+		}
+		if listMode && i == listSel {
+			fg = fg | termbox.AttrReverse
+		}
+
+		name := []rune(fmt.Sprintf("%d %s", item.desk, item.Name))
+		if len(name) > listWidth {
+			name = append(name[:listWidth-1], '…')
+		}
+		drawString(29, row, string(name), fg)
+	}
+}
+
+// listKey handles key event when choosing window for a region.
+func listKey(ev termbox.Event) {
+	switch keymap.Action(ev) {
+	case "up":
+		if listSel > 0 {
+			listSel--
+		}
+	case "down":
+		if listSel < len(listItems)-1 {
+			listSel++
+		}
+	case "choose", "mark":
+		item := &listItems[listSel]
+		if ri := assigned(item); ri >= 0 {
+			// a window can be in one place only
+			regions[ri].win = nil
+		}
+		regions[len(regions)-1].win = item
+		listMode = false
+	case "cancel", "unmark":
+		regions = regions[:len(regions)-1]
+		listMode = false
+	}
+}
+
+// applyRegions moves every assigned window to its region, bringing it
+// to the current desktop first.
+func applyRegions() error {
+	for _, r := range regions {
+		if r.win == nil {
+			continue
+		}
+		xw := r.win.XWin
+
+		if r.win.desk != curDesk {
+			if err := ewmh.WmDesktopReq(xu, xw, curDesk); err != nil {
+				return err
+			}
+		}
+
+		geom, err := wm.Geometry(xw)
+		if err != nil {
+			log.Printf("WARN: Geometry(%v): %v", xw, err)
+			continue
+		}
+
+		state := wm.PlacementState(xw)
+		pushUndo(xw, geom, state)
+		if len(state) > 0 {
+			if err := wm.SetState(xw, ewmh.StateRemove, state...); err != nil {
+				return err
+			}
+		}
+
+		x, y, w, h := cellRect(r.x0, r.y0, r.x1, r.y1)
+		x, y, w, h = fitRectFor(getSizeHints(xu, xw), wm.FrameExtents(xw), alignments[align].alignment, x, y, w, h)
+		if err := wm.MoveResize(xw, x, y, w, h); err != nil {
+			return err
+		}
+	}
+
+	return ewmh.ActiveWindowReq(xu, axw)
+}
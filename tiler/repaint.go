@@ -0,0 +1,80 @@
+package tiler
+
+import (
+	"log"
+	"time"
+
+	"github.com/BurntSushi/xgb/damage"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+const (
+	// longest wait for the first change after the terminal went away;
+	// the screen may be repainted already
+	repaintFirst = 200 * time.Millisecond
+	// changes this far apart are one repaint
+	repaintQuiet = 30 * time.Millisecond
+	// something keeps drawing, a video or such; don't wait for it
+	repaintMax = 500 * time.Millisecond
+)
+
+// waitRepaint waits until what was under the terminal and the outline
+// has been repainted, so that a screenshot or a click gets what's
+// there and not what's left of them. Damage on the root window
+// reports drawing anywhere on the screen.
+func waitRepaint() {
+	c := xu.Conn()
+	if err := damage.Init(c); err != nil {
+		log.Printf("WARN: no DAMAGE extension, can't tell when screen is repainted: %v", err)
+		time.Sleep(repaintFirst)
+		return
+	}
+	if _, err := damage.QueryVersion(c, 1, 1).Reply(); err != nil {
+		log.Printf("WARN: damage.QueryVersion: %v", err)
+		time.Sleep(repaintFirst)
+		return
+	}
+
+	dmg, err := damage.NewDamageId(c)
+	if err != nil {
+		log.Printf("WARN: damage.NewDamageId: %v", err)
+		time.Sleep(repaintFirst)
+		return
+	}
+	err = damage.CreateChecked(c, dmg, xproto.Drawable(xu.RootWin()), damage.ReportLevelNonEmpty).Check()
+	if err != nil {
+		log.Printf("WARN: damage.Create: %v", err)
+		time.Sleep(repaintFirst)
+		return
+	}
+	defer damage.Destroy(c, dmg)
+
+	wait := repaintFirst
+	for end := time.Now().Add(repaintMax); time.Now().Before(end); {
+		if !damaged(dmg, wait) {
+			return
+		}
+		// report the next change too
+		damage.Subtract(c, dmg, 0, 0)
+		wait = repaintQuiet
+	}
+}
+
+// damaged tells whether dmg reported a change within timeout.
+func damaged(dmg damage.Damage, timeout time.Duration) bool {
+	for end := time.Now().Add(timeout); time.Now().Before(end); {
+		ev, xerr := xu.Conn().PollForEvent()
+		if xerr != nil {
+			log.Printf("WARN: %v", xerr)
+			continue
+		}
+		if ev == nil {
+			time.Sleep(5 * time.Millisecond)
+			continue
+		}
+		if ev, ok := ev.(damage.NotifyEvent); ok && ev.Damage == dmg {
+			return true
+		}
+	}
+	return false
+}
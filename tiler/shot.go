@@ -0,0 +1,252 @@
+package tiler
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/xgraphics"
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/config"
+)
+
+// ShotCommand is the "shot" command of xdwim.
+var ShotCommand = xdwim.Command{
+	Name:     "shot",
+	Synopsis: "take a screenshot of a region picked on the tiler grid",
+	Run:      RunShot,
+	Usage:    shotUsage,
+}
+
+var shotFlags = flag.NewFlagSet("shot", flag.ContinueOnError)
+
+var (
+	outFlag  = shotFlags.String("o", "", "write PNG to `FILE`, - for stdout (default: a new file in the shot directory)")
+	clipFlag = shotFlags.Bool("clip", false, "put the PNG in the clipboard too (needs xclip)")
+)
+
+func shotUsage(prog string) {
+	fmt.Fprintf(os.Stderr, `Usage: %s [OPTIONS] [select|window|head]
+
+  select  pick a region on the grid (default)
+  window  the active window
+  head    the head with the active window
+
+Options:
+`, prog)
+	shotFlags.PrintDefaults()
+}
+
+// In shot mode the grid selects a region of the screen instead of a
+// window geometry. Actions in windowActions are ignored.
+var (
+	shotMode   = false
+	shotTarget = ""
+)
+
+// RunShot takes a screenshot of a region, the active window or a
+// whole head, and saves it as PNG.
+func RunShot(s *xdwim.Session, args []string) error {
+	begin(s)
+
+	shotFlags.Usage = func() {}
+	if err := shotFlags.Parse(args); err != nil || shotFlags.NArg() > 1 {
+		return xdwim.ErrUsage
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := configure(cfg.Tiler); err != nil {
+		return err
+	}
+	if err := LoadPresets(cfg.Tiler); err != nil {
+		return err
+	}
+
+	clip := *clipFlag || cfg.Shot.Clipboard
+	if _, err := exec.LookPath("xclip"); clip && err != nil {
+		return errors.New("putting screenshots in the clipboard needs xclip, which is not installed")
+	}
+
+	shotTarget = shotFlags.Arg(0)
+	switch shotTarget {
+	case "", "select":
+		if err := shotSelect(); err != nil {
+			return err
+		}
+	case "window", "head":
+		if err := setup(); err != nil {
+			return err
+		}
+	default:
+		return xdwim.ErrUsage
+	}
+
+	var r xrect.Rect
+	switch shotTarget {
+	case "window":
+		r = origGeom
+	case "head":
+		r = head
+	case "":
+		// cancelled
+		return nil
+	default:
+		// no gap between cells here
+		x, w := gridSpan(head.Width(), posX, markX)
+		y, h := gridSpan(head.Height(), posY, markY)
+		r = xrect.New(head.X()+x, head.Y()+y, w, h)
+	}
+
+	data, err := capture(r)
+	if err != nil {
+		return err
+	}
+
+	if clip {
+		xclip := exec.Command("xclip", "-selection", "clipboard", "-t", "image/png")
+		xclip.Stdin = bytes.NewReader(data)
+		xclip.Stderr = os.Stderr
+		if err := xclip.Run(); err != nil {
+			return fmt.Errorf("xclip: %v", err)
+		}
+	}
+
+	switch *outFlag {
+	case "-":
+		_, err = os.Stdout.Write(data)
+		return err
+	case "":
+		dir := cfg.Shot.Dir
+		if dir == "" {
+			dir = os.Getenv("HOME")
+		}
+		path := filepath.Join(dir, time.Now().Format("shot-20060102-150405.png"))
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	default:
+		return ioutil.WriteFile(*outFlag, data, 0644)
+	}
+}
+
+// shotSelect shows the grid on the active window's head, and sets
+// shotTarget to what the user chose, or to "" if they cancelled.
+func shotSelect() error {
+	if err := setup(); err == nil {
+		if err := loadNeighbours(); err != nil {
+			return err
+		}
+		posX, posY = origX1, origY1
+	} else {
+		// no active window; use the head with the pointer
+		axw = 0
+		ptr, err := xproto.QueryPointer(xu.Conn(), xu.RootWin()).Reply()
+		if err != nil {
+			return err
+		}
+		head, err = sess.HeadOf(xrect.New(int(ptr.RootX), int(ptr.RootY), 1, 1))
+		if err != nil {
+			return err
+		}
+		origX0, origX1, origY0, origY1 = -1, -1, -1, -1
+		posX, posY = gridSize/2, gridSize/2
+	}
+
+	if err := startPreview(); err != nil {
+		return err
+	}
+	shotMode = true
+	shotTarget = "select"
+	err := uiMain()
+	stopPreview()
+	if err != nil {
+		return err
+	}
+	if shotTarget == "select" && markX < 0 {
+		shotTarget = ""
+	}
+
+	// uiMain returns once urxvt has exited, but windows under it and
+	// the outline still have to repaint
+	waitRepaint()
+	return nil
+}
+
+// shotKey handles key event in shot mode. Returns true if the grid
+// is done.
+func shotKey(action string) (handled, done bool) {
+	switch action {
+	case "original":
+		if axw == 0 {
+			return true, false
+		}
+		shotTarget = "window"
+		return true, true
+	case "fullscreen":
+		shotTarget = "head"
+		return true, true
+	}
+	return windowActions[action], false
+}
+
+// capture takes a picture of the rectangle of the root window, and
+// encodes it as PNG.
+func capture(r xrect.Rect) ([]byte, error) {
+	root := xu.Screen()
+	r = clipRect(r, xrect.New(0, 0, int(root.WidthInPixels), int(root.HeightInPixels)))
+	if r == nil {
+		return nil, fmt.Errorf("nothing to capture")
+	}
+
+	img, err := xgraphics.NewDrawable(xu, xproto.Drawable(xu.RootWin()))
+	if err != nil {
+		return nil, err
+	}
+	defer img.Destroy()
+
+	var buf bytes.Buffer
+	sub := img.SubImage(image.Rect(r.X(), r.Y(), r.X()+r.Width(), r.Y()+r.Height()))
+	if err := png.Encode(&buf, sub); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// clipRect returns the part of r that is within bounds, or nil if
+// there's none.
+func clipRect(r, bounds xrect.Rect) xrect.Rect {
+	x0, y0 := r.X(), r.Y()
+	x1, y1 := x0+r.Width(), y0+r.Height()
+	if x0 < bounds.X() {
+		x0 = bounds.X()
+	}
+	if y0 < bounds.Y() {
+		y0 = bounds.Y()
+	}
+	if x1 > bounds.X()+bounds.Width() {
+		x1 = bounds.X() + bounds.Width()
+	}
+	if y1 > bounds.Y()+bounds.Height() {
+		y1 = bounds.Y() + bounds.Height()
+	}
+	if x1 <= x0 || y1 <= y0 {
+		return nil
+	}
+	return xrect.New(x0, y0, x1-x0, y1-y0)
+}
@@ -0,0 +1,150 @@
+package tiler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/mpasternacki/termbox-go"
+)
+
+// choosing window to swap places with from the list
+var swapMode = false
+
+// setupSwap prepares list of windows to swap with the active one.
+func setupSwap() error {
+	if err := loadList(); err != nil {
+		return err
+	}
+
+	for i, item := range listItems {
+		if item.XWin == axw {
+			listItems = append(listItems[:i], listItems[i+1:]...)
+			break
+		}
+	}
+	if len(listItems) == 0 {
+		return fmt.Errorf("no other windows")
+	}
+
+	swapMode = true
+	listMode = true
+	return nil
+}
+
+// swapTarget returns neighbour selected in the list, if it's on the
+// grid.
+func swapTarget() *neighbour {
+	if !swapMode || listSel < 0 {
+		return nil
+	}
+	for i := range neighbours {
+		if neighbours[i].XWin == listItems[listSel].XWin {
+			return &neighbours[i]
+		}
+	}
+	return nil
+}
+
+// swapKey handles key event in swap mode, returning true when done.
+func swapKey(ev termbox.Event) bool {
+	switch keymap.Action(ev) {
+	case "cancel", "unmark":
+		listSel = -1
+		return true
+	case "choose", "mark":
+		return true
+	case "next-window", "corner":
+		// next window visible on the grid
+		for i := 1; i <= len(listItems); i++ {
+			next := (listSel + i) % len(listItems)
+			for _, n := range neighbours {
+				if n.XWin == listItems[next].XWin {
+					listSel = next
+					return false
+				}
+			}
+		}
+	case "up":
+		if listSel > 0 {
+			listSel--
+		}
+	case "down":
+		if listSel < len(listItems)-1 {
+			listSel++
+		}
+	}
+	return false
+}
+
+// swap exchanges geometry, state and desktop of the active window and
+// other one.
+func swap(other xproto.Window) error {
+	type winInfo struct {
+		win   xproto.Window
+		geom  box
+		state []string
+		desk  uint
+	}
+
+	wins := []winInfo{{win: axw}, {win: other}}
+	for i := range wins {
+		w := &wins[i]
+
+		geom, err := wm.Geometry(w.win)
+		if err != nil {
+			return err
+		}
+		w.geom = rectBox(geom)
+		w.state = wm.PlacementState(w.win)
+		pushUndo(w.win, geom, w.state)
+
+		w.desk, err = ewmh.WmDesktopGet(xu, w.win)
+		if err != nil {
+			return err
+		}
+
+		if len(w.state) > 0 {
+			if err := wm.SetState(w.win, ewmh.StateRemove, w.state...); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, w := range wins {
+		o := wins[1-i]
+
+		if w.desk != o.desk {
+			if err := ewmh.WmDesktopReq(xu, w.win, o.desk); err != nil {
+				return err
+			}
+		}
+
+		// Geometry is the outer one, MoveResize takes care of each
+		// window's own frame
+		x, y, width, height := fitRectFor(
+			getSizeHints(xu, w.win), wm.FrameExtents(w.win), alignments[align].alignment,
+			o.geom.x0, o.geom.y0, o.geom.x1-o.geom.x0, o.geom.y1-o.geom.y0)
+		if err := wm.MoveResize(w.win, x, y, width, height); err != nil {
+			return err
+		}
+
+		if len(o.state) > 0 {
+			if err := wm.SetState(w.win, ewmh.StateAdd, o.state...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return ewmh.ActiveWindowReq(xu, axw)
+}
+
+// parseWindow parses window id, decimal or 0x-prefixed hex.
+func parseWindow(s string) (xproto.Window, error) {
+	id, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("bad window id %q: %v", s, err)
+	}
+	return xproto.Window(id), nil
+}
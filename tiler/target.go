@@ -0,0 +1,160 @@
+package tiler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/icccm"
+	"github.com/BurntSushi/xgbutil/xcursor"
+
+	"github.com/mpasternacki/xdwim/daemon"
+	"github.com/mpasternacki/xdwim/desktop"
+)
+
+var (
+	windowFlag = flags.String("window", "", "work on window with this `ID` instead of the active one")
+	pickFlag   = flags.Bool("pick", false, "work on window clicked with the mouse instead of the active one")
+	matchFlag  = flags.String("match", "", "work on first window matching `RULE` instead of the active one:\n"+
+		"class=REGEX, instance=REGEX, title=REGEX, or just REGEX to match class or title")
+)
+
+var errPickCancelled = errors.New("pick cancelled")
+
+// target returns the window to work on, as chosen by the flags.
+func target() (xproto.Window, error) {
+	switch {
+	case *windowFlag != "":
+		return parseWindow(*windowFlag)
+	case *pickFlag:
+		return pick()
+	case *matchFlag != "":
+		return match(*matchFlag)
+	default:
+		return sess.ActiveWindow()
+	}
+}
+
+// pick lets user click a window, and returns its client window. Any
+// button other than the left one cancels.
+func pick() (xproto.Window, error) {
+	cursor, err := xcursor.CreateCursor(xu, xcursor.Crosshair)
+	if err != nil {
+		return 0, err
+	}
+
+	grab, err := xproto.GrabPointer(xu.Conn(), false, xu.RootWin(),
+		xproto.EventMaskButtonPress, xproto.GrabModeAsync, xproto.GrabModeAsync,
+		xproto.WindowNone, cursor, xproto.TimeCurrentTime).Reply()
+	if err != nil {
+		return 0, err
+	}
+	if grab.Status != xproto.GrabStatusSuccess {
+		return 0, fmt.Errorf("can't grab pointer (status %d)", grab.Status)
+	}
+	defer func() {
+		xproto.UngrabPointer(xu.Conn(), xproto.TimeCurrentTime)
+		xu.Sync()
+	}()
+
+	for {
+		ev, xerr := xu.Conn().WaitForEvent()
+		if xerr != nil {
+			return 0, xerr
+		}
+		if ev == nil {
+			return 0, errors.New("X connection closed")
+		}
+
+		bp, ok := ev.(xproto.ButtonPressEvent)
+		if !ok {
+			continue
+		}
+		if bp.Detail != 1 || bp.Child == xproto.WindowNone {
+			return 0, errPickCancelled
+		}
+		// Child is the WM's frame, look for the client inside
+		return clientWindow(bp.Child)
+	}
+}
+
+// clientWindow finds the window with WM_STATE (the client) in the
+// tree under win.
+func clientWindow(win xproto.Window) (xproto.Window, error) {
+	if _, err := icccm.WmStateGet(xu, win); err == nil {
+		return win, nil
+	}
+
+	tree, err := xproto.QueryTree(xu.Conn(), win).Reply()
+	if err != nil {
+		return 0, err
+	}
+	for _, child := range tree.Children {
+		if client, err := clientWindow(child); err == nil {
+			return client, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no client window in %v", win)
+}
+
+// match returns first window matching the rule, looking at the current
+// desktop first.
+func match(rule string) (xproto.Window, error) {
+	field := ""
+	pattern := rule
+	if i := strings.Index(rule, "="); i > 0 {
+		switch rule[:i] {
+		case "class", "instance", "title":
+			field, pattern = rule[:i], rule[i+1:]
+		}
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return 0, err
+	}
+
+	desks, err := daemon.Desktops(sess)
+	if err != nil {
+		return 0, err
+	}
+
+	var wins []desktop.Window
+	for _, desk := range desks {
+		if desk.IsCurrent {
+			wins = append(wins, desk.Windows...)
+		}
+	}
+	for _, desk := range desks {
+		if !desk.IsCurrent {
+			wins = append(wins, desk.Windows...)
+		}
+	}
+
+	for _, win := range wins {
+		class, instance := "", ""
+		if wmClass, err := icccm.WmClassGet(xu, win.XWin); err == nil {
+			class, instance = wmClass.Class, wmClass.Instance
+		}
+
+		var ok bool
+		switch field {
+		case "class":
+			ok = re.MatchString(class)
+		case "instance":
+			ok = re.MatchString(instance)
+		case "title":
+			ok = re.MatchString(win.Name)
+		default:
+			ok = re.MatchString(class) || re.MatchString(win.Name)
+		}
+		if ok {
+			return win.XWin, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no window matches %q", rule)
+}
@@ -0,0 +1,469 @@
+package tiler
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xprop"
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/mpasternacki/xdwim"
+	"github.com/mpasternacki/xdwim/config"
+	"github.com/mpasternacki/xdwim/netwm"
+)
+
+var flags = flag.NewFlagSet("tile", flag.ContinueOnError)
+
+var (
+	sess    *xdwim.Session
+	xu      *xgbutil.XUtil
+	wm      *netwm.WM
+	presets []preset
+	cycles  []cycle
+	align   = 0 // index into alignments
+
+	// window & screen properties
+	axw       xproto.Window
+	origGeom  xrect.Rect
+	origState []string
+	head      xrect.Rect
+	hints     sizeHints
+	extents   ewmh.FrameExtents
+)
+
+// begin starts a call of one of the exported functions: all the state
+// left from a previous one is reset, except presets and cycles.
+func begin(s *xdwim.Session) {
+	sess, xu, wm = s, s.X, s.WM
+	align = 0
+
+	axw, origGeom, origState, head = 0, nil, nil, nil
+	hints, extents = sizeHints{}, ewmh.FrameExtents{}
+
+	origX0, origX1, origY0, origY1 = 0, 0, 0, 1
+	posX, posY, markX, markY = 0, 0, -1, -1
+	prefix, preIdx = 1, -1
+	setState, doUndo = nil, false
+	pendingOp, pixelDir = "", ""
+
+	neighbours = nil
+	regionMode, regions = false, nil
+	listMode, listItems, listSel, listTop = false, nil, 0, 0
+	swapMode = false
+	pv, liveMoved = nil, false
+
+	pointerMode, areas, clickButton, doWarp = false, nil, 0, false
+	shotMode, shotTarget = false, ""
+}
+
+// cellRect returns the outer geometry for the grid rectangle between
+// (x0, y0) and (x1, y1) inclusive, with the gap between cells.
+func cellRect(x0, y0, x1, y1 int) (x, y, w, h int) {
+	x, w = gridSpan(head.Width(), x0, x1)
+	y, h = gridSpan(head.Height(), y0, y1)
+	return gapRect(xrect.New(head.X()+x, head.Y()+y, w, h), head)
+}
+
+// gapRect takes the gap between cells off those sides of r that are
+// not on an edge of area.
+func gapRect(r, area xrect.Rect) (x, y, w, h int) {
+	x, w = gapSpan(area.Width(), r.X()-area.X(), r.Width())
+	y, h = gapSpan(area.Height(), r.Y()-area.Y(), r.Height())
+	return area.X() + x, area.Y() + y, w, h
+}
+
+// fitRect shrinks the outer geometry to the nearest size the client
+// accepts, and aligns the result within the original rectangle.
+func fitRect(x, y, w, h int) (int, int, int, int) {
+	return fitRectFor(hints, extents, alignments[align].alignment, x, y, w, h)
+}
+
+// fitRectFor is fitRect for a window with the given hints and extents,
+// and explicit alignment.
+func fitRectFor(sh sizeHints, ext ewmh.FrameExtents, al alignment, x, y, w, h int) (int, int, int, int) {
+	// size hints apply to the client, not to the frame
+	bw := ext.Left + ext.Right
+	bh := ext.Top + ext.Bottom
+	cw, ch := sh.fit(w-bw, h-bh)
+	fw, fh := cw+bw, ch+bh
+	fx, fy := al.place(x, y, w, h, fw, fh)
+	return fx, fy, fw, fh
+}
+
+// setup finds the window to work on (the active one, unless flags say
+// otherwise) and its head, and figures out where it is on the grid.
+func setup() error {
+	var err error
+	axw, err = target()
+	if err != nil {
+		return err
+	}
+	return setupWindow()
+}
+
+// setupWindow is setup for axw.
+func setupWindow() error {
+	geom, err := wm.Geometry(axw)
+	if err != nil {
+		return err
+	}
+	origGeom = geom
+	origState = wm.PlacementState(axw)
+
+	head, err = sess.HeadOf(geom)
+	if err != nil {
+		return err
+	}
+
+	// Figure out original position on grid, rounded to grid lines
+	x0, x1, y0, y1 := geom.X(), geom.X()+geom.Width(), geom.Y(), geom.Y()+geom.Height()
+	origX0, origX1 = gridCells(head.Width(), x0-head.X(), x1-head.X())
+	origY0, origY1 = gridCells(head.Height(), y0-head.Y(), y1-head.Y())
+
+	hints = getSizeHints(xu, axw)
+	extents = wm.FrameExtents(axw)
+
+	return nil
+}
+
+// place moves the active window to the outer geometry and focuses it,
+// remembering where it was for undo.
+func place(x, y, w, h int) error {
+	pushUndo(axw, origGeom, origState)
+	return moveTo(x, y, w, h)
+}
+
+func moveTo(x, y, w, h int) error {
+	err := wm.MoveResize(axw, x, y, w, h)
+	if err != nil {
+		return err
+	}
+
+	// window's been moved, any cycle starts over
+	if atom, err := xprop.Atm(xu, cycleProp); err != nil {
+		log.Printf("WARN: Atm(%v): %v", cycleProp, err)
+	} else if err := xproto.DeletePropertyChecked(xu.Conn(), axw, atom).Check(); err != nil {
+		log.Printf("WARN: DeleteProperty(%v, %v): %v", axw, cycleProp, err)
+	}
+
+	return ewmh.ActiveWindowReq(xu, axw)
+}
+
+func interactive() error {
+	err := startPreview()
+	if err != nil {
+		return err
+	}
+
+	// Maximized or fullscreen windows ignore MoveResize, or the WM
+	// reverts it as soon as it re-applies the state. Drop the state
+	// for now and put it back if user cancels.
+	if len(origState) > 0 {
+		err := wm.SetState(axw, ewmh.StateRemove, origState...)
+		if err != nil {
+			stopPreview()
+			return err
+		}
+	}
+	restoreState := func() {
+		if liveMoved {
+			restoreGeom()
+		}
+		if len(origState) > 0 {
+			if err := wm.SetState(axw, ewmh.StateAdd, origState...); err != nil {
+				log.Println("ERROR restoring state:", err)
+			}
+		}
+	}
+
+	err = uiMain()
+	stopPreview()
+	if err != nil {
+		restoreState()
+		return err
+	}
+
+	if doUndo {
+		if err := undo(); err != nil {
+			// nothing to undo; don't leave the window without its state
+			restoreState()
+			return err
+		}
+		return nil
+	}
+
+	if pixelDir != "" {
+		return applyPixelOp(pendingOp, pixelDir)
+	}
+
+	if setState != nil {
+		pushUndo(axw, origGeom, origState)
+		err = wm.SetState(axw, ewmh.StateAdd, setState...)
+		if err != nil {
+			return err
+		}
+		return ewmh.ActiveWindowReq(xu, axw)
+	}
+
+	if markX < 0 {
+		restoreState()
+		return nil
+	}
+
+	return place(fitRect(cellRect(posX, posY, markX, markY)))
+}
+
+func startPreview() error {
+	if !*previewFlag {
+		return nil
+	}
+	var err error
+	pv, err = newPreview()
+	return err
+}
+
+func stopPreview() {
+	if pv != nil {
+		pv.destroy()
+		pv = nil
+	}
+}
+
+// apply moves the active window to a preset without showing the grid.
+func apply(p preset) error {
+	if len(origState) > 0 {
+		err := wm.SetState(axw, ewmh.StateRemove, origState...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return place(fitRect(cellRect(p.cells())))
+}
+
+func usage(prog string) {
+	// with -h, Main asks for help before run reads the config
+	if presets == nil {
+		if cfg, err := config.Load(); err == nil {
+			presets, _ = loadPresets(cfg.Tiler.Presets)
+			cycles, _ = loadCycles(cfg.Tiler.Cycles, presets)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Usage:")
+	w := tabwriter.NewWriter(os.Stderr, 0, 8, 1, ' ', 0)
+	for _, line := range [][2]string{
+		{"[OPTIONS]", "choose position on a grid"},
+		{"apply PRESET", "move active window to a preset"},
+		{"apply X,Y,W,H", "move active window to a grid rectangle"},
+		{"cycle CYCLE", "move active window to next preset in a cycle"},
+		{"undo", "move active window back to where it was before"},
+		{"auto [LAYOUT]", "arrange all windows on current desktop and head"},
+		{"regions", "draw regions on a grid and put a window in each"},
+		{"nudge", "move & resize active window by small steps"},
+		{"swap [ID]", "swap places of active window and another one"},
+		{"grow DIR", "grow active window towards DIR (left, right, up"},
+		{"", "down) until it touches another window or screen edge"},
+		{"shrink DIR", "pull active window's DIR edge back so it doesn't"},
+		{"", "overlap other windows"},
+		{"move DIR", "move active window towards DIR until it touches"},
+		{"", "another window or screen edge"},
+	} {
+		if line[0] == "" {
+			fmt.Fprintf(w, "\t\t%s\n", line[1])
+		} else {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", prog, line[0], line[1])
+		}
+	}
+	w.Flush()
+
+	fmt.Fprintln(os.Stderr, "\nPresets:")
+	for _, p := range presets {
+		fmt.Fprintln(os.Stderr, " ", p)
+	}
+	fmt.Fprintln(os.Stderr, "\nCycles:")
+	for _, c := range cycles {
+		fmt.Fprintln(os.Stderr, " ", c)
+	}
+	fmt.Fprintln(os.Stderr, "\nLayouts:")
+	for _, l := range layouts {
+		fmt.Fprintln(os.Stderr, " ", l.Name)
+	}
+	fmt.Fprintf(os.Stderr, "\nMore presets and cycles can be defined in %s\n", config.Path())
+	fmt.Fprintln(os.Stderr, "\nAll commands work on the active window, unless -window, -pick or\n-match option says otherwise.")
+	fmt.Fprintln(os.Stderr, "\nOptions:")
+	flags.PrintDefaults()
+}
+
+// Command is the "tile" command of xdwim.
+var Command = xdwim.Command{
+	Name:     "tile",
+	Synopsis: "move and resize windows on a grid",
+	Run:      Run,
+	Usage:    usage,
+}
+
+// Run parses the tiler's arguments and runs the tiler command they
+// name, interactive grid by default.
+func Run(s *xdwim.Session, args []string) error {
+	begin(s)
+
+	if err := run(args); err != errPickCancelled {
+		return err
+	}
+	return nil
+}
+
+func run(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := configure(cfg.Tiler); err != nil {
+		return err
+	}
+
+	presets, err = loadPresets(cfg.Tiler.Presets)
+	if err != nil {
+		return err
+	}
+
+	cycles, err = loadCycles(cfg.Tiler.Cycles, presets)
+	if err != nil {
+		return err
+	}
+
+	flags.Usage = func() {}
+	if err := flags.Parse(args); err != nil {
+		return xdwim.ErrUsage
+	}
+
+	switch flags.Arg(0) {
+	case "":
+		if err := setup(); err != nil {
+			return err
+		}
+		if err := loadNeighbours(); err != nil {
+			return err
+		}
+		return interactive()
+	case "apply":
+		if flags.NArg() != 2 {
+			return xdwim.ErrUsage
+		}
+		p, err := findPreset(presets, flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		if err := setup(); err != nil {
+			return err
+		}
+		return apply(p)
+	case "cycle":
+		if flags.NArg() != 2 {
+			return xdwim.ErrUsage
+		}
+		if err := setup(); err != nil {
+			return err
+		}
+		return applyCycle(flags.Arg(1))
+	case "undo":
+		if flags.NArg() != 1 {
+			return xdwim.ErrUsage
+		}
+		if err := setup(); err != nil {
+			return err
+		}
+		return undo()
+	case "auto":
+		if flags.NArg() > 2 {
+			return xdwim.ErrUsage
+		}
+		if err := setup(); err != nil {
+			return err
+		}
+		return autoTile(flags.Arg(1))
+	case "grow", "shrink", "move":
+		if flags.NArg() != 2 {
+			return xdwim.ErrUsage
+		}
+		if err := setup(); err != nil {
+			return err
+		}
+		if err := loadNeighbours(); err != nil {
+			return err
+		}
+		return applyPixelOp(flags.Arg(0), flags.Arg(1))
+	case "nudge":
+		if flags.NArg() != 1 {
+			return xdwim.ErrUsage
+		}
+		if err := setup(); err != nil {
+			return err
+		}
+		step := *stepFlag
+		if step == "" {
+			step = cfg.Tiler.Step
+		}
+		return nudge(step)
+	case "swap":
+		if flags.NArg() > 2 {
+			return xdwim.ErrUsage
+		}
+		if err := setup(); err != nil {
+			return err
+		}
+		if flags.NArg() == 2 {
+			other, err := parseWindow(flags.Arg(1))
+			if err != nil {
+				return err
+			}
+			return swap(other)
+		}
+		if err := loadNeighbours(); err != nil {
+			return err
+		}
+		if err := setupSwap(); err != nil {
+			return err
+		}
+		if err := uiMain(); err != nil {
+			return err
+		}
+		if listSel < 0 {
+			return nil
+		}
+		return swap(listItems[listSel].XWin)
+	case "regions":
+		if flags.NArg() != 1 {
+			return xdwim.ErrUsage
+		}
+		if err := setup(); err != nil {
+			return err
+		}
+		if err := loadList(); err != nil {
+			return err
+		}
+		regionMode = true
+		if err := loadNeighbours(); err != nil {
+			return err
+		}
+		if err := startPreview(); err != nil {
+			return err
+		}
+		err := uiMain()
+		stopPreview()
+		if err != nil {
+			return err
+		}
+		return applyRegions()
+	default:
+		return xdwim.ErrUsage
+	}
+}
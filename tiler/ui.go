@@ -0,0 +1,510 @@
+package tiler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mpasternacki/termbox-go"
+
+	"github.com/mpasternacki/xdwim/netwm"
+	"github.com/mpasternacki/xdwim/tui"
+	"github.com/mpasternacki/xdwim/urxvtermbox"
+)
+
+var (
+	origX0 = 0
+	origX1 = 0
+	origY0 = 0
+	origY1 = 1
+	posX   = 0
+	posY   = 0
+	markX  = -1
+	markY  = -1
+	prefix = 1
+	preIdx = -1 // last preset jumped to
+
+	// set instead of a geometry when not nil
+	setState []string
+	// restore previous geometry instead
+	doUndo = false
+	// grow/shrink/move window in pixels instead; pendingOp waits for
+	// the direction
+	pendingOp = ""
+	pixelDir  = ""
+)
+
+// directions for the pixel operations, named same as the actions
+// that move the cursor
+var opDirs = map[string]bool{
+	"left":  true,
+	"right": true,
+	"up":    true,
+	"down":  true,
+}
+
+// actions that change the window itself rather than select cells;
+// they make no sense when the selection is for something else
+var windowActions = map[string]bool{
+	"maximize":   true,
+	"max-horz":   true,
+	"max-vert":   true,
+	"fullscreen": true,
+	"grow":       true,
+	"shrink":     true,
+	"move":       true,
+	"undo":       true,
+}
+
+func drawString(x, y int, s string, fg termbox.Attribute) int {
+	for _, ch := range s {
+		termbox.SetCell(x, y, ch, fg, termbox.ColorDefault)
+		x++
+	}
+	return x
+}
+
+func draw() {
+	// axes
+	for i := 0; i < gridSize; i++ {
+		ch0 := ' '
+		if i >= 9 {
+			ch0 = '1'
+		}
+		ch1 := rune('0' + (i+1)%10)
+		fgX := colors["axis"]
+		fgY := colors["axis"]
+		if i == posX {
+			fgX = colors["axis-current"]
+		}
+		if i == posY {
+			fgY = colors["axis-current"]
+		}
+		termbox.SetCell(0, i+1, ch0, fgY, termbox.ColorDefault)
+		termbox.SetCell(1, i+1, ch1, fgY, termbox.ColorDefault)
+		termbox.SetCell(26, i+1, ch0, fgY, termbox.ColorDefault)
+		termbox.SetCell(27, i+1, ch1, fgY, termbox.ColorDefault)
+		termbox.SetCell(2*i+2, 0, ch0, fgX, termbox.ColorDefault)
+		termbox.SetCell(2*i+3, 0, ch1, fgX, termbox.ColorDefault)
+		termbox.SetCell(2*i+2, 13, ch0, fgX, termbox.ColorDefault)
+		termbox.SetCell(2*i+3, 13, ch1, fgX, termbox.ColorDefault)
+	}
+
+	// grid
+	for i := 0; i < gridSize; i++ {
+		for j := 0; j < gridSize; j++ {
+			// default fg & char
+			fg := colors["grid"]
+			ch := glyphs["grid"][0]
+
+			// other windows are dimmed
+			if n := neighbourAt(i, j); n != nil {
+				fg = colors["neighbour"]
+				if i == n.x0 && j == n.y0 {
+					ch = n.label
+				}
+			}
+
+			// original win dimensions are highlighted
+			if i >= origX0 && i <= origX1 && j >= origY0 && j <= origY1 {
+				fg = colors["original"]
+			}
+
+			// regions have their own colours
+			if ri := regionAt(i, j); ri >= 0 {
+				fg = regionColor(ri)
+				ch = glyphs["region"][0]
+			}
+
+			// window to swap with is highlighted
+			if n := swapTarget(); n != nil &&
+				n.x0 <= i && i <= n.x1 && n.y0 <= j && j <= n.y1 {
+				fg = colors["swap"]
+			}
+
+			// cursor is solid
+			if i == posX && j == posY {
+				ch = glyphs["cursor"][0]
+				fg = colors["cursor"]
+			} else if markX >= 0 && markY >= 0 {
+				// besides cursor, selected block is more solid
+				l, r, t, b := posX, markX, posY, markY
+				if l > r {
+					l, r = r, l
+				}
+				if t > b {
+					t, b = b, t
+				}
+				if l <= i && i <= r && t <= j && j <= b {
+					ch = glyphs["selection"][0]
+				}
+			}
+
+			// bold/regular checkers
+			if (i+j)%2 == 1 {
+				fg = fg | termbox.AttrBold
+			}
+
+			ch2 := ch
+			if n := neighbourAt(i, j); n != nil && ch == n.label {
+				// label takes just the first half
+				ch2 = ' '
+			}
+
+			termbox.SetCell(2*i+2, j+1, ch, fg, termbox.ColorDefault)
+			termbox.SetCell(2*i+3, j+1, ch2, fg, termbox.ColorDefault)
+		}
+	}
+
+	// prefix
+	prfg := colors["prefix"]
+	if prefix == 1 {
+		prfg = colors["no-prefix"]
+	}
+	pr0 := ' '
+	if prefix >= 10 {
+		pr0 = '1'
+	}
+	pr1 := rune('0' + prefix%10)
+	switch pendingOp {
+	case "grow":
+		pr0, pr1, prfg = '<', '>', colors["pending"]
+	case "shrink":
+		pr0, pr1, prfg = '>', '<', colors["pending"]
+	case "move":
+		pr0, pr1, prfg = '<', '-', colors["pending"]
+	}
+	termbox.SetCell(0, 0, pr0, prfg, termbox.ColorDefault)
+	termbox.SetCell(1, 0, pr1, prfg, termbox.ColorDefault)
+
+	// status: alignment, and the size window will actually get
+	for i := 0; i < 28; i++ {
+		termbox.SetCell(i, 14, ' ', termbox.ColorDefault, termbox.ColorDefault)
+	}
+	termbox.SetCell(0, 14, alignments[align].glyph, colors["align"], termbox.ColorDefault)
+	if markX >= 0 {
+		x, y, w, h := cellRect(posX, posY, markX, markY)
+		_, _, fw, fh := fitRect(x, y, w, h)
+		col := drawString(2, 14, fmt.Sprintf("%d×%d", fw, fh), colors["size"])
+		if fw != w || fh != h {
+			// window can't fill the selection exactly
+			drawString(col, 14, fmt.Sprintf(" ≠ %d×%d", w, h), colors["warning"])
+		}
+	}
+
+	// name of preset matching the selection, or of the window under
+	// cursor
+	for i := 0; i < 28; i++ {
+		termbox.SetCell(i, 15, ' ', termbox.ColorDefault, termbox.ColorDefault)
+	}
+	label := ""
+	if markX >= 0 {
+		for _, p := range presets {
+			x0, y0, x1, y1 := p.cells()
+			if (x0 == posX && y0 == posY && x1 == markX && y1 == markY) ||
+				(x0 == markX && y0 == markY && x1 == posX && y1 == posY) {
+				label = p.Name
+				break
+			}
+		}
+	}
+	if label != "" {
+		drawString(0, 15, label, colors["preset"])
+	} else if n := neighbourAt(posX, posY); n != nil {
+		name := []rune(string(n.label) + " " + n.Name)
+		if len(name) > 28 {
+			name = append(name[:27], '…')
+		}
+		drawString(0, 15, string(name), colors["label"])
+	}
+
+	if pointerMode {
+		drawPointerStatus()
+	}
+
+	if regionMode || swapMode {
+		drawList()
+	}
+
+	termbox.Flush()
+
+	if pointerMode {
+		updatePointerPreview()
+	} else if !swapMode {
+		updatePreview()
+	}
+}
+
+// jumpPreset selects the preset that is delta positions away from the
+// last one.
+func jumpPreset(delta int) {
+	if len(presets) == 0 {
+		return
+	}
+	if preIdx < 0 && delta < 0 {
+		preIdx = 0
+	}
+	preIdx = (preIdx + delta + len(presets)) % len(presets)
+	markX, markY, posX, posY = presets[preIdx].cells()
+}
+
+func mousePos(ev termbox.Event) (x int, y int) {
+	x, y = (ev.MouseX-2)/2, ev.MouseY-1
+	if x < 0 {
+		x = 0
+	}
+	if x > gridSize-1 {
+		x = gridSize - 1
+	}
+	if y < 0 {
+		y = 0
+	}
+	if y > gridSize-1 {
+		y = gridSize - 1
+	}
+	return
+}
+
+func doMove(dx, dy int) {
+	posX += dx
+	if posX < 0 {
+		posX = 0
+	}
+	if posX > gridSize-1 {
+		posX = gridSize - 1
+	}
+	posY += dy
+	if posY < 0 {
+		posY = 0
+	}
+	if posY > gridSize-1 {
+		posY = gridSize - 1
+	}
+}
+
+func uiMain() error {
+	width := 28
+	if regionMode || swapMode {
+		width += 1 + listWidth
+	}
+
+	if fini, err := urxvtermbox.TermboxUrxvt(width, 16, urxvtArgs...); err != nil {
+		return err
+	} else {
+		defer fini()
+	}
+
+	tui.SetInputMode(termbox.InputMouse)
+
+	draw()
+	mouseHold := false
+	for {
+		ev := tui.PollEvent()
+		if swapMode && ev.Type == termbox.EventKey {
+			if swapKey(ev) {
+				return nil
+			}
+			draw()
+			continue
+		}
+
+		if listMode && ev.Type == termbox.EventKey {
+			listKey(ev)
+			draw()
+			continue
+		}
+
+		if pointerMode && ev.Type == termbox.EventKey {
+			if handled, done := pointerKey(ev); done {
+				return nil
+			} else if handled {
+				draw()
+				continue
+			}
+		}
+
+		if shotMode && ev.Type == termbox.EventKey {
+			if handled, done := shotKey(keymap.Action(ev)); done {
+				return nil
+			} else if handled {
+				continue
+			}
+		}
+
+		if pendingOp != "" && ev.Type == termbox.EventKey {
+			if dir := keymap.Action(ev); opDirs[dir] {
+				pixelDir = dir
+				return nil
+			}
+			// anything else cancels the operation
+			pendingOp = ""
+			draw()
+			continue
+		}
+
+		switch ev.Type {
+		case termbox.EventKey:
+			action := keymap.Action(ev)
+			if regionMode && windowActions[action] {
+				break
+			}
+			switch action {
+			case "cancel":
+				markX = -1
+				markY = -1
+				regions = nil
+				return nil
+			case "up":
+				doMove(0, -prefix)
+				prefix = 1
+			case "down":
+				doMove(0, prefix)
+				prefix = 1
+			case "left":
+				doMove(-prefix, 0)
+				prefix = 1
+			case "right":
+				doMove(prefix, 0)
+				prefix = 1
+			case "top":
+				posY = 0
+				prefix = 1
+			case "bottom":
+				posY = gridSize - 1
+				prefix = 1
+			case "leftmost":
+				posX = 0
+				prefix = 1
+			case "rightmost":
+				posX = gridSize - 1
+				prefix = 1
+			case "choose":
+				if regionMode {
+					if markX >= 0 {
+						addRegion()
+						break
+					}
+					if len(regions) > 0 {
+						return nil
+					}
+				}
+				if markX >= 0 {
+					return nil
+				}
+				fallthrough
+			case "mark":
+				markX, markY = posX, posY
+				prefix = 1
+			case "corner":
+				if markX >= 0 {
+					markX, posX = posX, markX
+					markY, posY = posY, markY
+				}
+				prefix = 1
+			case "unmark":
+				markX = -1
+				markY = -1
+				prefix = 1
+			case "original":
+				posX = origX1
+				posY = origY1
+				markX = origX0
+				markY = origY0
+				prefix = 1
+			case "full-width":
+				if posX < markX {
+					posX = 0
+					markX = gridSize - 1
+				} else {
+					posX = gridSize - 1
+					markX = 0
+				}
+				if markY < 0 {
+					markY = posY
+				}
+			case "full-height":
+				if posY < markY {
+					posY = 0
+					markY = gridSize - 1
+				} else {
+					posY = gridSize - 1
+					markY = 0
+				}
+				if markX < 0 {
+					markX = posX
+				}
+			case "column":
+				posX = prefix - 1
+				prefix = 1
+			case "row":
+				posY = prefix - 1
+				prefix = 1
+			case "maximize":
+				setState = []string{netwm.StateMaximizedHorz, netwm.StateMaximizedVert}
+				return nil
+			case "max-horz":
+				setState = []string{netwm.StateMaximizedHorz}
+				return nil
+			case "max-vert":
+				setState = []string{netwm.StateMaximizedVert}
+				return nil
+			case "fullscreen":
+				setState = []string{netwm.StateFullscreen}
+				return nil
+			case "free":
+				if x0, y0, x1, y1, ok := largestFree(); ok {
+					markX, markY, posX, posY = x0, y0, x1, y1
+				}
+				prefix = 1
+			case "grow", "shrink", "move":
+				pendingOp = action
+			case "undo":
+				doUndo = true
+				return nil
+			case "next-preset":
+				jumpPreset(1)
+				prefix = 1
+			case "prev-preset":
+				jumpPreset(-1)
+				prefix = 1
+			case "align":
+				align = (align + 1) % len(alignments)
+			default:
+				if strings.HasPrefix(action, "prefix-") {
+					prefix, _ = strconv.Atoi(action[len("prefix-"):])
+				}
+			}
+		case termbox.EventMouse:
+			if pointerMode {
+				break
+			}
+			switch ev.Key {
+			case termbox.MouseLeft:
+				posX, posY = mousePos(ev)
+				if !mouseHold {
+					markX, markY = posX, posY
+				}
+				mouseHold = true
+			case termbox.MouseRight:
+				markX, markY = mousePos(ev)
+			case termbox.MouseRelease:
+				mouseHold = false
+			}
+		case termbox.EventInterrupt:
+			markX = -1
+			markY = -1
+			regions = nil
+			if swapMode {
+				// swap only on explicit choice
+				listSel = -1
+			}
+			return nil
+		case termbox.EventError:
+			return ev.Err
+		}
+		draw()
+	}
+
+	return errors.New("CAN'T HAPPEN")
+}
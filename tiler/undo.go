@@ -0,0 +1,127 @@
+package tiler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xprop"
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/mpasternacki/xdwim/netwm"
+)
+
+// X property on the client with geometries it had before tiling, as
+// CARDINAL[][5] of x, y, w, h, placement state bits; last is newest.
+const undoProp = "_XDWIM_TILER_UNDO"
+
+const undoDepth = 16
+
+var errNoUndo = errors.New("nothing to undo")
+
+type undoEntry struct {
+	geom  xrect.Rect
+	state []string
+}
+
+func stateBits(states []string) uint {
+	var bits uint
+	for i, ps := range netwm.PlacementStates {
+		for _, state := range states {
+			if state == ps {
+				bits |= 1 << uint(i)
+			}
+		}
+	}
+	return bits
+}
+
+func bitsState(bits uint) []string {
+	var states []string
+	for i, ps := range netwm.PlacementStates {
+		if bits&(1<<uint(i)) != 0 {
+			states = append(states, ps)
+		}
+	}
+	return states
+}
+
+func readUndo(win xproto.Window) []undoEntry {
+	nums, err := xprop.PropValNums(xprop.GetProperty(xu, win, undoProp))
+	if err != nil {
+		return nil
+	}
+
+	entries := make([]undoEntry, 0, len(nums)/5)
+	for i := 0; i+5 <= len(nums); i += 5 {
+		// coordinates may be negative
+		entries = append(entries, undoEntry{
+			geom: xrect.New(
+				int(int32(nums[i])), int(int32(nums[i+1])),
+				int(nums[i+2]), int(nums[i+3])),
+			state: bitsState(nums[i+4]),
+		})
+	}
+	return entries
+}
+
+func writeUndo(win xproto.Window, entries []undoEntry) error {
+	if len(entries) == 0 {
+		atom, err := xprop.Atm(xu, undoProp)
+		if err != nil {
+			return err
+		}
+		return xproto.DeletePropertyChecked(xu.Conn(), win, atom).Check()
+	}
+
+	if len(entries) > undoDepth {
+		entries = entries[len(entries)-undoDepth:]
+	}
+
+	nums := make([]uint, 0, 5*len(entries))
+	for _, e := range entries {
+		x, y, w, h := e.geom.Pieces()
+		nums = append(nums, uint(x), uint(y), uint(w), uint(h), stateBits(e.state))
+	}
+	return xprop.ChangeProp32(xu, win, undoProp, "CARDINAL", nums...)
+}
+
+// pushUndo remembers window's geometry and state from before the tiler
+// touched it.
+func pushUndo(win xproto.Window, geom xrect.Rect, state []string) {
+	err := writeUndo(win, append(readUndo(win), undoEntry{geom, state}))
+	if err != nil {
+		log.Printf("WARN: can't save undo history: %v", err)
+	}
+}
+
+// undo puts the active window back where it was before it was last
+// tiled.
+func undo() error {
+	entries := readUndo(axw)
+	if len(entries) == 0 {
+		return errNoUndo
+	}
+	last := entries[len(entries)-1]
+
+	if state := wm.PlacementState(axw); len(state) > 0 {
+		err := wm.SetState(axw, ewmh.StateRemove, state...)
+		if err != nil {
+			return err
+		}
+	}
+
+	x, y, w, h := last.geom.Pieces()
+	if err := moveTo(x, y, w, h); err != nil {
+		return err
+	}
+
+	if len(last.state) > 0 {
+		if err := wm.SetState(axw, ewmh.StateAdd, last.state...); err != nil {
+			return err
+		}
+	}
+
+	return writeUndo(axw, entries[:len(entries)-1])
+}
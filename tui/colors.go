@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mpasternacki/termbox-go"
+)
+
+var colorNames = map[string]termbox.Attribute{
+	"default": termbox.ColorDefault,
+	"black":   termbox.ColorBlack,
+	"red":     termbox.ColorRed,
+	"green":   termbox.ColorGreen,
+	"yellow":  termbox.ColorYellow,
+	"blue":    termbox.ColorBlue,
+	"magenta": termbox.ColorMagenta,
+	"cyan":    termbox.ColorCyan,
+	"white":   termbox.ColorWhite,
+}
+
+var attrNames = map[string]termbox.Attribute{
+	"bold":      termbox.AttrBold,
+	"underline": termbox.AttrUnderline,
+	"reverse":   termbox.AttrReverse,
+}
+
+// ParseColor parses a colour name, optionally with attributes, like
+// "yellow" or "bold green".
+func ParseColor(s string) (termbox.Attribute, error) {
+	var attr termbox.Attribute
+	color := false
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		if c, ok := colorNames[word]; ok && !color {
+			attr |= c
+			color = true
+		} else if a, ok := attrNames[word]; ok {
+			attr |= a
+		} else {
+			return 0, fmt.Errorf("bad colour %q", s)
+		}
+	}
+	return attr, nil
+}
+
+// Colors returns defaults with colours from the config replacing
+// them.
+func Colors(defaults map[string]termbox.Attribute, cfg map[string]string) (map[string]termbox.Attribute, error) {
+	colors := make(map[string]termbox.Attribute, len(defaults))
+	for name, c := range defaults {
+		colors[name] = c
+	}
+
+	for name, s := range cfg {
+		if _, ok := defaults[name]; !ok {
+			return nil, fmt.Errorf("unknown colour %q (known: %s)", name, strings.Join(colorKeys(defaults), ", "))
+		}
+		c, err := ParseColor(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		colors[name] = c
+	}
+
+	return colors, nil
+}
+
+// Glyphs returns defaults with glyphs from the config replacing
+// them. A glyph must have as many characters as its default.
+func Glyphs(defaults map[string]string, cfg map[string]string) (map[string][]rune, error) {
+	glyphs := make(map[string][]rune, len(defaults))
+	for name, g := range defaults {
+		glyphs[name] = []rune(g)
+	}
+
+	for name, g := range cfg {
+		def, ok := defaults[name]
+		if !ok {
+			names := make([]string, 0, len(defaults))
+			for name := range defaults {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown glyph %q (known: %s)", name, strings.Join(names, ", "))
+		}
+		if n := utf8.RuneCountInString(def); utf8.RuneCountInString(g) != n {
+			return nil, fmt.Errorf("%s: %q should be %d characters, like %q", name, g, n, def)
+		}
+		glyphs[name] = []rune(g)
+	}
+
+	return glyphs, nil
+}
+
+func colorKeys(m map[string]termbox.Attribute) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
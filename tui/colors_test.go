@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/mpasternacki/termbox-go"
+)
+
+func TestParseColor(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want termbox.Attribute
+		ok   bool
+	}{
+		{"yellow", termbox.ColorYellow, true},
+		{"Bold Green", termbox.ColorGreen | termbox.AttrBold, true},
+		{"reverse", termbox.AttrReverse, true},
+		{"underline bold red", termbox.ColorRed | termbox.AttrUnderline | termbox.AttrBold, true},
+		{"", 0, true},
+		{"red green", 0, false},
+		{"purple", 0, false},
+		{"blinking red", 0, false},
+	} {
+		c, err := ParseColor(tc.s)
+		if (err == nil) != tc.ok {
+			t.Errorf("ParseColor(%q): error %v", tc.s, err)
+		} else if c != tc.want {
+			t.Errorf("ParseColor(%q) = %v; want %v", tc.s, c, tc.want)
+		}
+	}
+}
+
+func TestColors(t *testing.T) {
+	defaults := map[string]termbox.Attribute{
+		"label":  termbox.ColorBlue,
+		"urgent": termbox.ColorRed,
+	}
+
+	colors, err := Colors(defaults, map[string]string{"urgent": "bold magenta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if colors["label"] != termbox.ColorBlue || colors["urgent"] != termbox.ColorMagenta|termbox.AttrBold {
+		t.Errorf("Colors = %v", colors)
+	}
+	if defaults["urgent"] != termbox.ColorRed {
+		t.Error("Colors changed the defaults")
+	}
+
+	for _, cfg := range []map[string]string{
+		{"title": "red"},
+		{"label": "sky blue"},
+	} {
+		if _, err := Colors(defaults, cfg); err == nil {
+			t.Errorf("Colors(%v): no error", cfg)
+		}
+	}
+}
+
+func TestGlyphs(t *testing.T) {
+	defaults := map[string]string{"frame": "┌┐└┘─│", "cursor": "▶"}
+
+	glyphs, err := Glyphs(defaults, map[string]string{"frame": "++++-|"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(glyphs["frame"]) != "++++-|" || string(glyphs["cursor"]) != "▶" {
+		t.Errorf("Glyphs = %q", glyphs)
+	}
+
+	for _, cfg := range []map[string]string{
+		{"frame": "+-|"},
+		{"arrow": ">"},
+	} {
+		if _, err := Glyphs(defaults, cfg); err == nil {
+			t.Errorf("Glyphs(%v): no error", cfg)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/mpasternacki/termbox-go"
+)
+
+// Arrow keys with Shift or Ctrl. termbox doesn't know them; PollEvent
+// reports them from the sequences urxvt sends.
+const (
+	KeyShiftUp termbox.Key = 0x1000 + iota
+	KeyShiftDown
+	KeyShiftRight
+	KeyShiftLeft
+	KeyCtrlUp
+	KeyCtrlDown
+	KeyCtrlRight
+	KeyCtrlLeft
+)
+
+func init() {
+	for name, key := range map[string]termbox.Key{
+		"shift-up":    KeyShiftUp,
+		"shift-down":  KeyShiftDown,
+		"shift-right": KeyShiftRight,
+		"shift-left":  KeyShiftLeft,
+		"ctrl-up":     KeyCtrlUp,
+		"ctrl-down":   KeyCtrlDown,
+		"ctrl-right":  KeyCtrlRight,
+		"ctrl-left":   KeyCtrlLeft,
+	} {
+		keyNames[name] = key
+	}
+}
+
+// SetInputMode sets the termbox input mode PollEvent needs, with
+// extra flags like termbox.InputMouse.
+func SetInputMode(extra termbox.InputMode) termbox.InputMode {
+	return termbox.SetInputMode(termbox.InputEsc | extra)
+}
+
+// how long a lone Esc waits for the rest of a sequence; urxvt sends it
+// in one write, so it's normally there already
+const escDelay = 25 * time.Millisecond
+
+var (
+	// events read ahead and not returned yet
+	queued []termbox.Event
+	// outstanding termbox.PollEvent; it can't be cancelled, so a read
+	// that timed out is picked up by the next call
+	reading chan termbox.Event
+)
+
+func read(timeout time.Duration) (termbox.Event, bool) {
+	if reading == nil {
+		reading = make(chan termbox.Event, 1)
+		go func(ch chan termbox.Event) {
+			ch <- termbox.PollEvent()
+		}(reading)
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = time.After(timeout)
+	}
+	select {
+	case ev := <-reading:
+		reading = nil
+		return ev, true
+	case <-expired:
+		return termbox.Event{}, false
+	}
+}
+
+// PollEvent is termbox.PollEvent that also recognises Shift- and
+// Ctrl-arrows: urxvt sends them as Esc [ a–d and Esc O a–d, which
+// termbox in Esc mode reports as Esc followed by two characters.
+func PollEvent() termbox.Event {
+	if len(queued) > 0 {
+		ev := queued[0]
+		queued = queued[1:]
+		return ev
+	}
+
+	ev, _ := read(0)
+	if ev.Type != termbox.EventKey || ev.Key != termbox.KeyEsc || ev.Mod != 0 {
+		return ev
+	}
+
+	intro, ok := read(escDelay)
+	if !ok {
+		return ev
+	}
+	var base termbox.Key
+	switch {
+	case intro.Type == termbox.EventKey && intro.Ch == '[':
+		base = KeyShiftUp
+	case intro.Type == termbox.EventKey && intro.Ch == 'O':
+		base = KeyCtrlUp
+	default:
+		queued = append(queued, intro)
+		return ev
+	}
+
+	dir, ok := read(escDelay)
+	if !ok {
+		queued = append(queued, intro)
+		return ev
+	}
+	if dir.Type != termbox.EventKey || dir.Ch < 'a' || dir.Ch > 'd' {
+		queued = append(queued, intro, dir)
+		return ev
+	}
+	return termbox.Event{Type: termbox.EventKey, Key: base + termbox.Key(dir.Ch-'a')}
+}
@@ -0,0 +1,163 @@
+// Package tui has the configurable bits of xdwim's terminal UIs:
+// key bindings, colours and glyphs.
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mpasternacki/termbox-go"
+)
+
+// Key is a single key press: a special key, or a character.
+type Key struct {
+	Key termbox.Key
+	Ch  rune
+}
+
+var keyNames = map[string]termbox.Key{
+	"esc":       termbox.KeyEsc,
+	"enter":     termbox.KeyEnter,
+	"tab":       termbox.KeyTab,
+	"backspace": termbox.KeyBackspace,
+	"insert":    termbox.KeyInsert,
+	"delete":    termbox.KeyDelete,
+	"home":      termbox.KeyHome,
+	"end":       termbox.KeyEnd,
+	"pgup":      termbox.KeyPgup,
+	"pgdn":      termbox.KeyPgdn,
+	"up":        termbox.KeyArrowUp,
+	"down":      termbox.KeyArrowDown,
+	"left":      termbox.KeyArrowLeft,
+	"right":     termbox.KeyArrowRight,
+	"f1":        termbox.KeyF1,
+	"f2":        termbox.KeyF2,
+	"f3":        termbox.KeyF3,
+	"f4":        termbox.KeyF4,
+	"f5":        termbox.KeyF5,
+	"f6":        termbox.KeyF6,
+	"f7":        termbox.KeyF7,
+	"f8":        termbox.KeyF8,
+	"f9":        termbox.KeyF9,
+	"f10":       termbox.KeyF10,
+	"f11":       termbox.KeyF11,
+	"f12":       termbox.KeyF12,
+}
+
+// EventKey returns the key pressed in a key event.
+func EventKey(ev termbox.Event) Key {
+	switch {
+	case ev.Ch != 0:
+		return Key{Ch: ev.Ch}
+	case ev.Key == termbox.KeySpace:
+		return Key{Ch: ' '}
+	case ev.Key == termbox.KeyBackspace2:
+		return Key{Key: termbox.KeyBackspace}
+	}
+	return Key{Key: ev.Key}
+}
+
+// ParseKey parses a key as written in the config file: a single
+// character, one of the special key names ("esc", "enter", "left",
+// "f1", ...), "space", or "ctrl-" and a letter.
+func ParseKey(s string) (Key, error) {
+	if utf8.RuneCountInString(s) == 1 {
+		r, _ := utf8.DecodeRuneInString(s)
+		return Key{Ch: r}, nil
+	}
+
+	name := strings.ToLower(s)
+	if name == "space" {
+		return Key{Ch: ' '}, nil
+	}
+	if k, ok := keyNames[name]; ok {
+		return Key{Key: k}, nil
+	}
+	if strings.HasPrefix(name, "ctrl-") && len(name) == 6 {
+		if c := name[5]; c >= 'a' && c <= 'z' {
+			return Key{Key: termbox.Key(c - 'a' + 1)}, nil
+		}
+	}
+
+	return Key{}, fmt.Errorf("unknown key %q", s)
+}
+
+func (k Key) String() string {
+	if k.Ch == ' ' {
+		return "space"
+	}
+	if k.Ch != 0 {
+		return string(k.Ch)
+	}
+	for name, key := range keyNames {
+		if key == k.Key {
+			return name
+		}
+	}
+	if k.Key >= termbox.KeyCtrlA && k.Key <= termbox.KeyCtrlZ {
+		return "ctrl-" + string(rune('a'+k.Key-termbox.KeyCtrlA))
+	}
+	return fmt.Sprintf("key-%#x", int(k.Key))
+}
+
+// Keymap tells which action a key does.
+type Keymap map[Key]string
+
+// NewKeymap binds keys to actions. It starts with the defaults; an
+// action listed in bindings gets just the keys listed there instead.
+// Actions not in defaults and keys bound to two actions are errors.
+func NewKeymap(defaults, bindings map[string][]string) (Keymap, error) {
+	for action := range bindings {
+		if _, ok := defaults[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q (known: %s)", action, strings.Join(actions(defaults), ", "))
+		}
+	}
+
+	km := make(Keymap)
+	for _, action := range actions(defaults) {
+		keys, ok := bindings[action]
+		if !ok {
+			keys = defaults[action]
+		}
+		for _, s := range keys {
+			k, err := ParseKey(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", action, err)
+			}
+			if other, ok := km[k]; ok && other != action {
+				return nil, fmt.Errorf("key %v bound to both %s and %s", k, other, action)
+			}
+			km[k] = action
+		}
+	}
+
+	return km, nil
+}
+
+// Action returns the action bound to the key of the event, or "".
+func (km Keymap) Action(ev termbox.Event) string {
+	return km[EventKey(ev)]
+}
+
+// Keys returns the keys bound to the action.
+func (km Keymap) Keys(action string) []Key {
+	var keys []Key
+	for k, a := range km {
+		if a == action {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
+
+func actions(m map[string][]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
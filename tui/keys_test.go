@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/mpasternacki/termbox-go"
+)
+
+func TestParseKey(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want Key
+		ok   bool
+	}{
+		{"q", Key{Ch: 'q'}, true},
+		{"Q", Key{Ch: 'Q'}, true},
+		{"←", Key{Ch: '←'}, true},
+		{"space", Key{Ch: ' '}, true},
+		{"esc", Key{Key: termbox.KeyEsc}, true},
+		{"Enter", Key{Key: termbox.KeyEnter}, true},
+		{"f12", Key{Key: termbox.KeyF12}, true},
+		{"ctrl-a", Key{Key: termbox.KeyCtrlA}, true},
+		{"ctrl-z", Key{Key: termbox.KeyCtrlZ}, true},
+		{"shift-up", Key{Key: KeyShiftUp}, true},
+		{"ctrl-left", Key{Key: KeyCtrlLeft}, true},
+		{"", Key{}, false},
+		{"ctrl-1", Key{}, false},
+		{"ctrl-ab", Key{}, false},
+		{"hyper-x", Key{}, false},
+	} {
+		k, err := ParseKey(tc.s)
+		if (err == nil) != tc.ok {
+			t.Errorf("ParseKey(%q): error %v", tc.s, err)
+		} else if k != tc.want {
+			t.Errorf("ParseKey(%q) = %#v; want %#v", tc.s, k, tc.want)
+		}
+	}
+}
+
+func TestKeyStringRoundTrip(t *testing.T) {
+	for _, s := range []string{"q", "space", "esc", "enter", "pgdn", "f5", "ctrl-w", "shift-down", "ctrl-right"} {
+		k, err := ParseKey(s)
+		if err != nil {
+			t.Errorf("ParseKey(%q): %v", s, err)
+		} else if k.String() != s {
+			t.Errorf("ParseKey(%q).String() = %q", s, k.String())
+		}
+	}
+}
+
+func TestEventKey(t *testing.T) {
+	for _, tc := range []struct {
+		ev   termbox.Event
+		want Key
+	}{
+		{termbox.Event{Ch: 'x'}, Key{Ch: 'x'}},
+		{termbox.Event{Key: termbox.KeySpace}, Key{Ch: ' '}},
+		{termbox.Event{Key: termbox.KeyBackspace2}, Key{Key: termbox.KeyBackspace}},
+		{termbox.Event{Key: termbox.KeyArrowUp}, Key{Key: termbox.KeyArrowUp}},
+	} {
+		if k := EventKey(tc.ev); k != tc.want {
+			t.Errorf("EventKey(%#v) = %v; want %v", tc.ev, k, tc.want)
+		}
+	}
+}
+
+func TestNewKeymap(t *testing.T) {
+	defaults := map[string][]string{
+		"cancel": {"esc", "q"},
+		"choose": {"enter", "space"},
+		"up":     {"up", "k"},
+	}
+
+	km, err := NewKeymap(defaults, map[string][]string{
+		"cancel": {"ctrl-g"},
+		"up":     {"up", "k", "w"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		ev   termbox.Event
+		want string
+	}{
+		{termbox.Event{Key: termbox.KeyCtrlG}, "cancel"},
+		{termbox.Event{Key: termbox.KeyEsc}, ""}, // replaced, not added to
+		{termbox.Event{Ch: 'q'}, ""},
+		{termbox.Event{Key: termbox.KeySpace}, "choose"},
+		{termbox.Event{Ch: 'w'}, "up"},
+		{termbox.Event{Key: termbox.KeyArrowUp}, "up"},
+	} {
+		if a := km.Action(tc.ev); a != tc.want {
+			t.Errorf("Action(%#v) = %q; want %q", tc.ev, a, tc.want)
+		}
+	}
+	if keys := km.Keys("up"); len(keys) != 3 || keys[0].String() != "k" {
+		t.Errorf("Keys(up) = %v", keys)
+	}
+
+	for _, bindings := range []map[string][]string{
+		{"fly": {"f"}},
+		{"up": {"enter"}},
+		{"cancel": {"nosuchkey"}},
+	} {
+		if _, err := NewKeymap(defaults, bindings); err == nil {
+			t.Errorf("NewKeymap(%v): no error", bindings)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package xdwim has what all the xdwim commands share: X connection
+// setup, finding the active window and its head, and running commands
+// with common flags and logging.
+package xdwim
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xinerama"
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/mpasternacki/xdwim/netwm"
+)
+
+// Session is an X connection along with what the WM supports.
+type Session struct {
+	X       *xgbutil.XUtil
+	WM      *netwm.WM
+	Display string
+}
+
+// Connect opens X connection to display, or to $DISPLAY if it's empty.
+func Connect(display string) (*Session, error) {
+	if display == "" {
+		display = os.Getenv("DISPLAY")
+	}
+
+	xu, err := xgbutil.NewConnDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+
+	wm, err := netwm.Detect(xu)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{X: xu, WM: wm, Display: display}, nil
+}
+
+func (s *Session) ActiveWindow() (xproto.Window, error) {
+	return ewmh.ActiveWindowGet(s.X)
+}
+
+// Heads returns the physical heads.
+func (s *Session) Heads() ([]xrect.Rect, error) {
+	heads, err := xinerama.PhysicalHeads(s.X)
+	if err != nil {
+		return nil, err
+	}
+	if len(heads) == 0 {
+		return nil, errors.New("no heads")
+	}
+	return heads, nil
+}
+
+// HeadIndex returns index of the head with the center of r on it, or
+// 0.
+func HeadIndex(heads []xrect.Rect, r xrect.Rect) int {
+	cx := r.X() + r.Width()/2
+	cy := r.Y() + r.Height()/2
+	for i, head := range heads {
+		if cx >= head.X() &&
+			cx < head.X()+head.Width() &&
+			cy >= head.Y() &&
+			cy < head.Y()+head.Height() {
+			return i
+		}
+	}
+	return 0
+}
+
+// HeadOf returns the physical head with the center of r on it, or the
+// first one.
+func (s *Session) HeadOf(r xrect.Rect) (xrect.Rect, error) {
+	heads, err := s.Heads()
+	if err != nil {
+		return nil, err
+	}
+	return heads[HeadIndex(heads, r)], nil
+}
+
+// ErrUsage is returned by a command's Run when it's called wrong;
+// Main prints the command's usage then.
+var ErrUsage = errors.New("usage")
+
+// Command is a single xdwim subcommand.
+type Command struct {
+	Name     string
+	Synopsis string
+	// Run parses args (without the command name) and does the work.
+	Run func(s *Session, args []string) error
+	// Usage prints help to stderr; prog is how the command was run,
+	// like "xdwim tile" or "tiler".
+	Usage func(prog string)
+}
+
+var (
+	displayFlag = flag.String("display", "", "X `DISPLAY` to connect to (default $DISPLAY)")
+	logFlag     = flag.String("log", "", "append log to `FILE` instead of stderr")
+)
+
+func mainUsage(commands []Command) {
+	fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS] COMMAND [ARGS]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.Name, cmd.Synopsis)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun with COMMAND -h for command's help.\n\nOptions:")
+	flag.PrintDefaults()
+}
+
+// wantsHelp tells whether command's args ask for help.
+func wantsHelp(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "--":
+			return false
+		case "-h", "-help", "--help":
+			return true
+		}
+	}
+	return false
+}
+
+// Main parses common flags, connects to X, and runs one of the
+// commands, named by the first argument. With just one command, its
+// name and the common flags are not needed: that's for the
+// single-purpose binaries.
+func Main(commands ...Command) {
+	var cmd *Command
+	var args []string
+
+	if len(commands) == 1 {
+		cmd = &commands[0]
+		args = os.Args[1:]
+	} else {
+		flag.Usage = func() { mainUsage(commands) }
+		flag.Parse()
+		if flag.NArg() == 0 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		for i := range commands {
+			if commands[i].Name == flag.Arg(0) {
+				cmd = &commands[i]
+			}
+		}
+		if cmd == nil {
+			flag.Usage()
+			os.Exit(2)
+		}
+		args = flag.Args()[1:]
+	}
+
+	prog := filepath.Base(os.Args[0])
+	if len(commands) > 1 {
+		prog += " " + cmd.Name
+	}
+
+	// no need for X to show help
+	if wantsHelp(args) {
+		cmd.Usage(prog)
+		os.Exit(0)
+	}
+
+	log.SetPrefix(cmd.Name + ": ")
+	if *logFlag != "" {
+		f, err := os.OpenFile(*logFlag, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		log.SetOutput(f)
+	}
+
+	s, err := Connect(*displayFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch err := cmd.Run(s, args); err {
+	case nil:
+	case ErrUsage:
+		cmd.Usage(prog)
+		os.Exit(2)
+	default:
+		log.Fatal(err)
+	}
+}